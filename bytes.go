@@ -0,0 +1,60 @@
+package trie
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// AddBytes is like Add but takes the key as a byte slice, which is
+// only copied into a string for the insertion itself.
+func (t *Trie) AddBytes(key []byte, meta interface{}) *Node {
+	return t.Add(string(key), meta)
+}
+
+// GetBytes is like Get but takes the key as a byte slice.
+func (t *Trie) GetBytes(key []byte) (interface{}, bool) {
+	node := t.live(t.terminalbytes(key))
+	if node == nil {
+		return nil, false
+	}
+
+	t.use(node)
+
+	return node.Meta(), true
+}
+
+// ContainsBytes is like Contains but takes the key as a byte slice.
+func (t *Trie) ContainsBytes(key []byte) bool {
+	return t.live(t.terminalbytes(key)) != nil
+}
+
+// terminalbytes is like terminal but decodes the runes straight
+// from key, lower casing them if needed. Only a trie with a
+// normalizer has to convert key to a string first.
+func (t *Trie) terminalbytes(key []byte) *Node {
+	if t.norm != nil {
+		return t.terminal(t.normalize(string(key)))
+	}
+
+	node := t.Root()
+	for len(key) > 0 {
+		r, size := utf8.DecodeRune(key)
+		key = key[size:]
+		if t.fold {
+			r = unicode.ToLower(r)
+		}
+
+		n, ok := node.children[r]
+		if !ok {
+			return nil
+		}
+		node = n
+	}
+
+	node, ok := node.children[nul]
+	if !ok || !node.alive() {
+		return nil
+	}
+
+	return node
+}
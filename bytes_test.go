@@ -0,0 +1,63 @@
+package trie
+
+import "testing"
+
+func TestBytes(t *testing.T) {
+	trie := NewTrie(CaseInsensitive())
+	trie.AddBytes([]byte("Foo"), 1)
+
+	if m, ok := trie.GetBytes([]byte("FOO")); !ok || m != 1 {
+		t.Errorf("expected foo with 1, got %v, %t", m, ok)
+	}
+	if trie.ContainsBytes([]byte("fo")) || !trie.ContainsBytes([]byte("foo")) {
+		t.Error("expected ContainsBytes to agree with Contains")
+	}
+}
+
+func TestBytesAllocs(t *testing.T) {
+	trie := NewTrie()
+	trie.AddBytes([]byte("foobar"), 1)
+	hit, miss := []byte("foobar"), []byte("foobaz")
+
+	if allocs := testing.AllocsPerRun(100, func() {
+		trie.GetBytes(hit)
+		trie.GetBytes(miss)
+		trie.ContainsBytes(hit)
+		trie.ContainsBytes(miss)
+	}); allocs != 0 {
+		t.Errorf("expected no allocations, got %v", allocs)
+	}
+}
+
+func BenchmarkGetBytesHit(b *testing.B) {
+	trie := NewTrie()
+	trie.AddBytes([]byte("foobar"), 1)
+	key := []byte("foobar")
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		trie.GetBytes(key)
+	}
+}
+
+func BenchmarkGetBytesMiss(b *testing.B) {
+	trie := NewTrie()
+	trie.AddBytes([]byte("foobar"), 1)
+	key := []byte("foobaz")
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		trie.GetBytes(key)
+	}
+}
+
+func BenchmarkContainsBytesMiss(b *testing.B) {
+	trie := NewTrie()
+	trie.AddBytes([]byte("foobar"), 1)
+	key := []byte("foo")
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		trie.ContainsBytes(key)
+	}
+}
@@ -0,0 +1,187 @@
+package trie
+
+import "container/list"
+
+// Filter returns a new trie with the keys of t for which pred returns
+// true, leaving t untouched. The new trie is configured like t, except
+// for the OnAdd, OnRemove and OnEvict functions, and the metas are
+// shared with t rather than copied.
+func (t *Trie) Filter(pred func(key string, meta interface{}) bool) *Trie {
+	res := t.derive()
+	collectfunc(t.Root(), nil, func(key []rune, n *Node) bool {
+		if k := string(key); pred(k, n.Meta()) {
+			res.copykey(k, n)
+		}
+		return true
+	})
+
+	return res
+}
+
+// Clone returns a copy of t which shares no nodes with it, so that
+// either of them can be changed without affecting the other. The
+// metas are shared rather than copied. Like Filter, Clone leaves out
+// the OnAdd, OnRemove and OnEvict functions.
+func (t *Trie) Clone() *Trie {
+	return t.CloneWith(nil)
+}
+
+// CloneWith is like Clone but sets every meta of the copy, including
+// the values added with AppendMeta, to the result of copyMeta called
+// with the meta of t.
+func (t *Trie) CloneWith(copyMeta func(meta interface{}) interface{}) *Trie {
+	res := t.derive()
+	res.size = t.size
+	res.total = t.total
+	res.tombs = t.tombs
+
+	var clones map[*Node]*Node
+	if t.lru != nil {
+		clones = make(map[*Node]*Node, t.size)
+	}
+
+	res.root = res.clonenode(t.Root(), nil, nil, copyMeta, clones)
+
+	if t.lru != nil {
+		for e := t.lru.Back(); e != nil; e = e.Prev() {
+			res.use(clones[e.Value.(*Node)])
+		}
+	}
+
+	if t.suffix != nil {
+		res.suffix = t.suffix.Clone()
+	}
+
+	return res
+}
+
+// clonenode returns a copy of src, and of every node below it, with
+// the given parent. The copy of every terminator node is recorded in
+// clones, if it isn't nil.
+func (t *Trie) clonenode(src, parent *Node, pre []rune, copyMeta func(interface{}) interface{}, clones map[*Node]*Node) *Node {
+	n := &Node{
+		val:      src.val,
+		term:     src.term,
+		meta:     src.meta,
+		mask:     src.mask,
+		best:     src.best,
+		parent:   parent,
+		children: make(map[rune]*Node, len(src.children)),
+	}
+
+	if src.term {
+		if copyMeta != nil {
+			n.meta = copyMeta(n.meta)
+		}
+
+		if src.ext != nil {
+			e := *src.ext
+			e.elem = nil
+			e.more = append([]interface{}(nil), src.ext.more...)
+			if copyMeta != nil {
+				for i, m := range e.more {
+					e.more[i] = copyMeta(m)
+				}
+			}
+			n.ext = &e
+		}
+
+		if n.alive() {
+			t.index(string(pre), n.meta)
+		}
+		if clones != nil {
+			clones[src] = n
+		}
+	}
+
+	for r, c := range src.children {
+		npre := pre
+		if !c.term {
+			npre = append(pre, r)
+		}
+		n.children[r] = t.clonenode(c, n, npre, copyMeta, clones)
+	}
+
+	return n
+}
+
+// SubtrieAt returns a new trie with the keys of t starting with
+// prefix, with prefix stripped off, so that prefix itself becomes the
+// empty key. The new trie is configured like t, as described for
+// Filter, and is empty if no key starts with prefix.
+func (t *Trie) SubtrieAt(prefix string) *Trie {
+	res := t.derive()
+
+	node := t.nodeAtPath(t.normalize(prefix))
+	if node == nil {
+		return res
+	}
+
+	collectfunc(node, nil, func(key []rune, n *Node) bool {
+		res.copykey(string(key), n)
+		return true
+	})
+
+	return res
+}
+
+// Split is like SubtrieAt but also removes the keys starting with
+// prefix from t, which is left untouched if there are none.
+func (t *Trie) Split(prefix string) *Trie {
+	res := t.SubtrieAt(prefix)
+	t.RemoveAll(prefix)
+	return res
+}
+
+// derive returns an empty trie configured like t, without
+// the functions called when keys are added or removed.
+func (t *Trie) derive() *Trie {
+	res := &Trie{
+		root:    newNode(nil, 0, 0, false),
+		weight:  t.weight,
+		recency: t.recency,
+		fold:    t.fold,
+		norm:    t.norm,
+		counted: t.counted,
+		now:     t.now,
+		max:     t.max,
+	}
+
+	if t.suffix != nil {
+		res.suffix = NewTrie()
+	}
+
+	if t.lru != nil {
+		res.lru = list.New()
+	}
+
+	if t.metas != nil {
+		res.metas = make(map[interface{}]map[string]struct{})
+	}
+
+	return res
+}
+
+// copykey adds the normalized key to t with the meta and the
+// per key state of the terminator node src of another trie.
+func (t *Trie) copykey(key string, src *Node) {
+	node, _ := t.put(key, func(interface{}, bool) interface{} {
+		return src.meta
+	})
+
+	if src.ext == nil {
+		return
+	}
+
+	e := node.entry()
+	elem := e.elem
+	*e = *src.ext
+	e.elem = elem
+	e.more = append([]interface{}(nil), src.ext.more...)
+
+	if t.counted {
+		// put counted key once already.
+		t.total += e.count - 1
+	}
+	t.reweigh(node)
+}
@@ -0,0 +1,65 @@
+package trie
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClone(t *testing.T) {
+	trie := NewTrie()
+	for _, key := range []string{"foo", "foobar", "bar"} {
+		trie.Add(key, nil)
+	}
+
+	clone := trie.Clone()
+	trie.Add("baz", nil)
+	trie.Remove("foo")
+	clone.Add("qux", nil)
+	clone.Remove("bar")
+
+	if keys := trie.Keys(); !reflect.DeepEqual(keys, []string{"bar", "baz", "foobar"}) {
+		t.Errorf("expected [bar baz foobar] in the original, got %v", keys)
+	}
+	if keys := clone.Keys(); !reflect.DeepEqual(keys, []string{"foo", "foobar", "qux"}) {
+		t.Errorf("expected [foo foobar qux] in the clone, got %v", keys)
+	}
+	if trie.Len() != 3 || clone.Len() != 3 {
+		t.Errorf("expected 3 keys in both, got %d and %d", trie.Len(), clone.Len())
+	}
+}
+
+func TestCloneParents(t *testing.T) {
+	trie := NewTrie()
+	trie.Add("ab", nil)
+	clone := trie.Clone()
+
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		for _, c := range n.children {
+			if c.Parent() != n {
+				t.Errorf("expected the parent of %v to be the cloned %v", c, n)
+			}
+			walk(c)
+		}
+	}
+	walk(clone.Root())
+
+	if clone.Root() == trie.Root() {
+		t.Error("expected the clone to have its own root")
+	}
+}
+
+func TestCloneWith(t *testing.T) {
+	trie := NewTrie()
+	trie.Add("foo", []int{1})
+
+	clone := trie.CloneWith(func(meta interface{}) interface{} {
+		return append([]int(nil), meta.([]int)...)
+	})
+	m, _ := clone.Get("foo")
+	m.([]int)[0] = 2
+
+	if m, _ := trie.Get("foo"); m.([]int)[0] != 1 {
+		t.Errorf("expected the meta of the original to be untouched, got %v", m)
+	}
+}
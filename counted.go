@@ -0,0 +1,33 @@
+package trie
+
+// Count returns how many times key was added, less the times it was
+// removed, if the trie was created with Counted. Otherwise it returns
+// 1 for a stored key. It returns 0 if key isn't stored.
+func (t *Trie) Count(key string) int {
+	key = t.normalize(key)
+	node := t.live(t.terminal(key))
+	if node == nil {
+		return 0
+	}
+
+	return node.count()
+}
+
+// TotalCount returns the sum of the counts of all keys. Without
+// Counted it is the same as Len.
+func (t *Trie) TotalCount() int {
+	if !t.counted {
+		return t.size
+	}
+
+	return t.total
+}
+
+// count returns the count of the terminator node n.
+func (n *Node) count() int {
+	if n.ext == nil || n.ext.count == 0 {
+		return 1
+	}
+
+	return n.ext.count
+}
@@ -0,0 +1,54 @@
+package trie
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCounted(t *testing.T) {
+	trie := NewTrie(Counted())
+	trie.Add("foo", nil)
+	trie.Add("bar", nil)
+	trie.Add("foo", nil)
+	trie.Remove("bar")
+	trie.Add("foo", nil)
+	trie.Add("bar", nil)
+	trie.Remove("foo")
+
+	if c := trie.Count("foo"); c != 2 {
+		t.Errorf("expected foo to be counted twice, got %d", c)
+	}
+	if c := trie.Count("bar"); c != 1 {
+		t.Errorf("expected bar to be counted once, got %d", c)
+	}
+	if keys := trie.Keys(); !reflect.DeepEqual(keys, []string{"bar", "foo"}) {
+		t.Errorf("expected [bar foo], got %v", keys)
+	}
+	if trie.Len() != 2 || trie.TotalCount() != 3 {
+		t.Errorf("expected 2 keys counted 3 times, got %d and %d", trie.Len(), trie.TotalCount())
+	}
+}
+
+func TestCountedRemovePastZero(t *testing.T) {
+	trie := NewTrie(Counted())
+	trie.Add("foo", nil)
+	trie.Add("foo", nil)
+
+	for i, want := range []bool{true, true, false, false} {
+		if removed := trie.Remove("foo"); removed != want {
+			t.Errorf("expected removal %d to report %t", i, want)
+		}
+	}
+
+	if c := trie.Count("foo"); c != 0 {
+		t.Errorf("expected foo to be gone, got a count of %d", c)
+	}
+	if trie.Len() != 0 || trie.TotalCount() != 0 {
+		t.Errorf("expected an empty trie, got %d keys counted %d times", trie.Len(), trie.TotalCount())
+	}
+
+	trie.Add("foo", nil)
+	if c := trie.Count("foo"); c != 1 {
+		t.Errorf("expected foo added again to be counted once, got %d", c)
+	}
+}
@@ -0,0 +1,80 @@
+package trie
+
+import "context"
+
+// checkInterval is the number of nodes visited
+// between two checks of the context.
+const checkInterval = 256
+
+// canceller tells a traversal to stop once its context is done.
+// A nil canceller never stops a traversal.
+type canceller struct {
+	ctx     context.Context
+	visited int
+	err     error
+}
+
+func (c *canceller) cancelled() bool {
+	if c == nil {
+		return false
+	}
+
+	if c.err == nil {
+		if c.visited++; c.visited%checkInterval == 0 {
+			c.err = c.ctx.Err()
+		}
+	}
+
+	return c.err != nil
+}
+
+// PrefixSearchCtx is like PrefixSearch but gives up once ctx is
+// done, returning the keys found so far along with ctx.Err().
+func (t *Trie) PrefixSearchCtx(ctx context.Context, pre string) ([]string, error) {
+	pre = t.normalize(pre)
+	var keys []string
+	if err := ctx.Err(); err != nil {
+		return keys, err
+	}
+
+	node := t.nodeAtPath(pre)
+	if node == nil {
+		return keys, nil
+	}
+
+	c := &canceller{ctx: ctx}
+	cancelcollect(node, []rune(pre), c, func(key []rune, _ *Node) bool {
+		keys = append(keys, string(key))
+		return true
+	})
+	return keys, c.err
+}
+
+// FuzzySearchCtx is like FuzzySearch but gives up once ctx is
+// done, returning the keys found so far along with ctx.Err().
+func (t *Trie) FuzzySearchCtx(ctx context.Context, partial string) ([]string, error) {
+	partial = t.normalize(partial)
+	var keys []string
+	if err := ctx.Err(); err != nil {
+		return keys, err
+	}
+
+	c := &canceller{ctx: ctx}
+	fuzzycollect(t.Root(), nil, []rune(partial), fuzzymatch{}, c, func(key []rune, _ *Node, _ fuzzymatch) bool {
+		keys = append(keys, string(key))
+		return true
+	})
+	return keys, c.err
+}
+
+// SearchWithinCtx is like SearchWithinDistance but gives up once
+// ctx is done, returning the keys found so far along with ctx.Err().
+func (t *Trie) SearchWithinCtx(ctx context.Context, key string, maxDistance int) ([]KeyDistance, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	c := &canceller{ctx: ctx}
+	res := t.searchWithin(key, maxDistance, false, c)
+	return res, c.err
+}
@@ -0,0 +1,102 @@
+package trie
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// expiringContext is a context whose deadline passes after its Err
+// method has been called a given number of times, so that it fires
+// in the middle of a traversal without depending on timing.
+type expiringContext struct {
+	context.Context
+	left int
+}
+
+func (c *expiringContext) Err() error {
+	if c.left--; c.left < 0 {
+		return context.DeadlineExceeded
+	}
+	return nil
+}
+
+func numberedTrie(n int) *Trie {
+	trie := NewTrie()
+	for i := 0; i < n; i++ {
+		trie.Add(fmt.Sprintf("key%04d", i), nil)
+	}
+	return trie
+}
+
+func TestSearchCtxCancelled(t *testing.T) {
+	trie := numberedTrie(10)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if keys, err := trie.PrefixSearchCtx(ctx, "key"); err != context.Canceled || len(keys) != 0 {
+		t.Errorf("expected no keys and context.Canceled, got %v and %v", keys, err)
+	}
+	if keys, err := trie.FuzzySearchCtx(ctx, "k"); err != context.Canceled || len(keys) != 0 {
+		t.Errorf("expected no keys and context.Canceled, got %v and %v", keys, err)
+	}
+	if res, err := trie.SearchWithinCtx(ctx, "key0001", 1); err != context.Canceled || len(res) != 0 {
+		t.Errorf("expected no keys and context.Canceled, got %v and %v", res, err)
+	}
+}
+
+func TestSearchCtxDeadline(t *testing.T) {
+	trie := numberedTrie(1000)
+
+	for _, search := range []func(context.Context) (int, error){
+		func(ctx context.Context) (int, error) {
+			keys, err := trie.PrefixSearchCtx(ctx, "key")
+			return len(keys), err
+		},
+		func(ctx context.Context) (int, error) {
+			keys, err := trie.FuzzySearchCtx(ctx, "k")
+			return len(keys), err
+		},
+		func(ctx context.Context) (int, error) {
+			res, err := trie.SearchWithinCtx(ctx, "key0001", 7)
+			return len(res), err
+		},
+	} {
+		// The deadline passes at the second check
+		// during the traversal.
+		ctx := &expiringContext{Context: context.Background(), left: 2}
+		n, err := search(ctx)
+		if err != context.DeadlineExceeded {
+			t.Errorf("expected context.DeadlineExceeded, got %v", err)
+		}
+		if n == 0 || n >= trie.Len() {
+			t.Errorf("expected some of the %d keys, got %d", trie.Len(), n)
+		}
+	}
+}
+
+func TestSearchCtxNoMatches(t *testing.T) {
+	// Below key there are many nodes but only a single key
+	// at the end, so the context has to be checked on the way.
+	trie := NewTrie()
+	trie.Add("key"+strings.Repeat("x", 4*checkInterval), nil)
+
+	for _, search := range []func(context.Context) error{
+		func(ctx context.Context) error {
+			_, err := trie.PrefixSearchCtx(ctx, "key")
+			return err
+		},
+		func(ctx context.Context) error {
+			_, err := trie.FuzzySearchCtx(ctx, "k")
+			return err
+		},
+	} {
+		// The deadline passes at the first check
+		// during the traversal.
+		ctx := &expiringContext{Context: context.Background(), left: 1}
+		if err := search(ctx); err != context.DeadlineExceeded {
+			t.Errorf("expected context.DeadlineExceeded, got %v", err)
+		}
+	}
+}
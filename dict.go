@@ -0,0 +1,98 @@
+package trie
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Match is an occurrence of a key inside a text.
+// Start and End are byte offsets into the text.
+type Match struct {
+	Key   string
+	Start int
+	End   int
+}
+
+// MatchIn returns every occurrence of every key inside text,
+// including overlapping ones, ordered by start and then by end
+// offset. The empty key never matches. Text is normalized like the
+// keys, one rune at a time, and Key holds the normalized match.
+//
+// A descent is started at every rune of text, so the cost is
+// bounded by the length of text times the length of the longest key.
+func (t *Trie) MatchIn(text string) []Match {
+	var matches []Match
+	for start := range text {
+		t.scan(text, start, func(end int, _ *Node) bool {
+			matches = append(matches, Match{Key: t.normalize(text[start:end]), Start: start, End: end})
+			return true
+		})
+	}
+
+	return matches
+}
+
+// scan descends the trie along text, beginning at byte offset
+// start, and calls fn with the end offset and terminator node of
+// every non empty key found, until fn returns false. With a
+// normalizer every rune of text is normalized on its own, which
+// keeps the offsets into text, so a normalizer which combines runes
+// only finds keys whose runes are combined in text already.
+func (t *Trie) scan(text string, start int, fn func(end int, term *Node) bool) {
+	node := t.Root()
+	for i := start; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if t.norm != nil {
+			for _, nr := range t.normalize(text[i : i+size]) {
+				if node = node.children[nr]; node == nil || node.term {
+					return
+				}
+			}
+		} else {
+			if t.fold {
+				r = unicode.ToLower(r)
+			}
+
+			if node = node.children[r]; node == nil || node.term {
+				return
+			}
+		}
+
+		i += size
+		if n, ok := node.children[nul]; ok && n.alive() {
+			if !fn(i, n) {
+				return
+			}
+		}
+	}
+}
+
+// ReplaceAll returns a copy of text in which the occurrences of
+// keys have been replaced by the result of repl. Matches are found
+// leftmost-longest: scanning from the left, the longest key starting
+// at the current position is replaced and scanning resumes after it,
+// so replaced occurrences never overlap. Text is normalized like in
+// MatchIn and repl is passed the normalized key.
+func (t *Trie) ReplaceAll(text string, repl func(key string, meta interface{}) string) string {
+	var b strings.Builder
+	for i := 0; i < len(text); {
+		end, term := -1, (*Node)(nil)
+		t.scan(text, i, func(e int, n *Node) bool {
+			end, term = e, n
+			return true
+		})
+
+		if end < 0 {
+			_, size := utf8.DecodeRuneInString(text[i:])
+			b.WriteString(text[i : i+size])
+			i += size
+			continue
+		}
+
+		b.WriteString(repl(t.normalize(text[i:end]), term.Meta()))
+		i = end
+	}
+
+	return b.String()
+}
@@ -0,0 +1,63 @@
+package trie
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMatchInNormalizer(t *testing.T) {
+	trie := NewTrie(WithNormalizer(strings.ToLower))
+	trie.Add("Foo", nil)
+
+	want := []Match{{Key: "foo", Start: 1, End: 4}}
+	if matches := trie.MatchIn("xFOOx"); !reflect.DeepEqual(matches, want) {
+		t.Errorf("expected %v, got %v", want, matches)
+	}
+}
+
+func TestReplaceAll(t *testing.T) {
+	trie := NewTrie()
+	for _, key := range []string{"ab", "abc", "bcd", "cd", "x"} {
+		trie.Add(key, nil)
+	}
+
+	upper := func(key string, _ interface{}) string {
+		return "[" + strings.ToUpper(key) + "]"
+	}
+
+	tests := []struct {
+		text, want string
+	}{
+		// abc is longer than ab, and bcd starts further right.
+		{"abcd", "[ABC]d"},
+		// The nested ab loses against abc around it.
+		{"zabcz", "z[ABC]z"},
+		// Adjacent matches are both replaced.
+		{"abcx", "[ABC][X]"},
+		{"xx", "[X][X]"},
+		// A miss at the left doesn't hide the match after it.
+		{"bbcd", "b[BCD]"},
+		{"zzz", "zzz"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := trie.ReplaceAll(tt.text, upper); got != tt.want {
+			t.Errorf("expected %q for %q, got %q", tt.want, tt.text, got)
+		}
+	}
+}
+
+func TestReplaceAllNormalizer(t *testing.T) {
+	trie := NewTrie(WithNormalizer(strings.ToLower))
+	trie.Add("Foo", nil)
+
+	mark := func(key string, _ interface{}) string {
+		return "[" + key + "]"
+	}
+
+	if got := trie.ReplaceAll("xFOOx", mark); got != "x[foo]x" {
+		t.Errorf("expected %q, got %q", "x[foo]x", got)
+	}
+}
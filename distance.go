@@ -0,0 +1,202 @@
+package trie
+
+import "sort"
+
+// KeyDistance is a key found by an edit distance search
+// together with its distance from the query.
+type KeyDistance struct {
+	Key      string
+	Distance int
+}
+
+// SearchWithin returns all keys within the given Levenshtein
+// distance of key in lexicographic order.
+func (t *Trie) SearchWithin(key string, maxDistance int) []string {
+	var keys []string
+	for _, kd := range t.SearchWithinDistance(key, maxDistance) {
+		keys = append(keys, kd.Key)
+	}
+
+	return keys
+}
+
+// SearchWithinDistance is like SearchWithin but also returns
+// the distance of every key from the query.
+func (t *Trie) SearchWithinDistance(key string, maxDistance int) []KeyDistance {
+	return t.searchWithin(key, maxDistance, false, nil)
+}
+
+// SearchWithinDamerau returns all keys within the given
+// Damerau-Levenshtein distance of key in lexicographic order, so
+// that a transposition of two adjacent runes counts as a single edit.
+func (t *Trie) SearchWithinDamerau(key string, maxDistance int) []string {
+	var keys []string
+	for _, kd := range t.SearchWithinDamerauDistance(key, maxDistance) {
+		keys = append(keys, kd.Key)
+	}
+
+	return keys
+}
+
+// SearchWithinDamerauDistance is like SearchWithinDamerau but also
+// returns the distance of every key from the query.
+func (t *Trie) SearchWithinDamerauDistance(key string, maxDistance int) []KeyDistance {
+	return t.searchWithin(key, maxDistance, true, nil)
+}
+
+func (t *Trie) searchWithin(key string, maxDistance int, damerau bool, c *canceller) []KeyDistance {
+	key = t.normalize(key)
+	var res []KeyDistance
+	if maxDistance < 0 {
+		return res
+	}
+
+	query := []rune(key)
+	row := make([]int, len(query)+1)
+	for i := range row {
+		row[i] = i
+	}
+
+	editcollect(t.Root(), nil, query, row, nil, maxDistance, damerau, &res, c)
+	return res
+}
+
+// editcollect walks the trie carrying the last rows of the dynamic
+// programming table, pruning every subtree whose rows can no longer
+// produce a distance of at most max. The row before last is only
+// needed, and only kept, when transpositions are counted.
+func editcollect(node *Node, pre, query []rune, prev, prevprev []int, max int, damerau bool, res *[]KeyDistance, c *canceller) {
+	prevlow := prev[0]
+	for _, d := range prev {
+		if d < prevlow {
+			prevlow = d
+		}
+	}
+
+	children := node.children
+	for _, r := range sortedrunes(children) {
+		n := children[r]
+		if c.cancelled() {
+			return
+		}
+
+		if n.term {
+			if d := prev[len(query)]; d <= max && n.alive() {
+				*res = append(*res, KeyDistance{Key: string(pre), Distance: d})
+			}
+			continue
+		}
+
+		row := make([]int, len(query)+1)
+		row[0] = prev[0] + 1
+		low := row[0]
+		for i := 1; i <= len(query); i++ {
+			cost := 1
+			if query[i-1] == r {
+				cost = 0
+			}
+
+			row[i] = min(row[i-1]+1, prev[i]+1, prev[i-1]+cost)
+			if damerau && prevprev != nil && i > 1 &&
+				query[i-1] == pre[len(pre)-1] && query[i-2] == r {
+				row[i] = min(row[i], prevprev[i-2]+1)
+			}
+
+			if row[i] < low {
+				low = row[i]
+			}
+		}
+
+		// A transposition can still bring the next row back
+		// within max through the current one.
+		if low > max && (!damerau || prevlow+1 > max) {
+			continue
+		}
+
+		var pp []int
+		if damerau {
+			pp = prev
+		}
+
+		editcollect(n, append(pre, r), query, row, pp, max, damerau, res, c)
+	}
+}
+
+// SuggestOne returns all keys exactly one deletion, insertion,
+// substitution or transposition away from word, in lexicographic
+// order. The word itself is never part of the result.
+//
+// Unlike SearchWithin no dynamic programming table is carried;
+// the walk allows a single divergence from word and then has to
+// follow the rest of word exactly.
+func (t *Trie) SuggestOne(word string) []string {
+	word = t.normalize(word)
+	seen := make(map[string]struct{})
+	suggestcollect(t.Root(), nil, []rune(word), false, seen)
+	delete(seen, word)
+
+	keys := make([]string, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+func suggestcollect(node *Node, pre, rest []rune, edited bool, seen map[string]struct{}) {
+	if edited {
+		for _, r := range rest {
+			n, ok := node.children[r]
+			if !ok || n.term {
+				return
+			}
+			pre, node = append(pre, r), n
+		}
+
+		if n, ok := node.children[nul]; ok && n.alive() {
+			seen[string(pre)] = struct{}{}
+		}
+		return
+	}
+
+	if len(rest) == 0 {
+		// Only an insertion at the end is left.
+		for r, n := range node.children {
+			if !n.term {
+				suggestcollect(n, append(pre, r), rest, true, seen)
+			}
+		}
+		return
+	}
+
+	// Deletion of rest[0].
+	suggestcollect(node, pre, rest[1:], true, seen)
+
+	m := maskruneslice(rest[1:])
+	for r, n := range node.children {
+		if n.term {
+			continue
+		}
+
+		npre := append(pre, r)
+		if r == rest[0] {
+			suggestcollect(n, npre, rest[1:], false, seen)
+		} else if m&^n.Mask() == 0 {
+			// Substitution of rest[0] by r.
+			suggestcollect(n, npre, rest[1:], true, seen)
+		}
+
+		// Insertion of r before rest[0].
+		if maskruneslice(rest)&^n.Mask() == 0 {
+			suggestcollect(n, npre, rest, true, seen)
+		}
+
+		// Transposition of rest[0] and rest[1].
+		if len(rest) > 1 && r == rest[1] {
+			if c, ok := n.children[rest[0]]; ok && !c.term {
+				suggestcollect(c, append(npre, rest[0]), rest[2:], true, seen)
+			}
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package trie
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSearchWithinOrder(t *testing.T) {
+	trie := NewTrie()
+	for _, key := range []string{"then", "the", "tea", "than", "cat"} {
+		trie.Add(key, nil)
+	}
+
+	want := []KeyDistance{{"tea", 2}, {"than", 2}, {"the", 0}, {"then", 1}}
+	for i := 0; i < 10; i++ {
+		if res := trie.SearchWithinDistance("the", 2); !reflect.DeepEqual(res, want) {
+			t.Fatalf("expected %v, got %v", want, res)
+		}
+	}
+}
+
+func TestSearchWithinDamerau(t *testing.T) {
+	trie := NewTrie()
+	trie.Add("the", nil)
+	trie.Add("then", nil)
+
+	if res := trie.SearchWithinDamerauDistance("teh", 1); !reflect.DeepEqual(res, []KeyDistance{{"the", 1}}) {
+		t.Errorf("expected the at distance 1, got %v", res)
+	}
+	if res := trie.SearchWithinDistance("teh", 1); len(res) != 0 {
+		t.Errorf("expected no key within Levenshtein distance 1, got %v", res)
+	}
+	if res := trie.SearchWithinDistance("teh", 2); !reflect.DeepEqual(res, []KeyDistance{{"the", 2}, {"then", 2}}) {
+		t.Errorf("expected the and then at distance 2, got %v", res)
+	}
+}
@@ -0,0 +1,46 @@
+package trie
+
+import "sort"
+
+// ScoredKey is a key found by a fuzzy search
+// together with the quality of the match.
+type ScoredKey struct {
+	Key   string
+	Score float64
+}
+
+// FuzzySearchScored is like FuzzySearch but scores every key and
+// returns the keys by descending score, with keys of equal score in
+// lexicographic order.
+//
+// With the query runes matched at their leftmost occurrence, the
+// score is the sum of the fraction of query runes matched right after
+// the previous one, 1/(1+i) for the position i of the first match,
+// and the length of the query divided by the length of the key, so
+// contiguous matches close to the start of short keys rank highest.
+func (t *Trie) FuzzySearchScored(partial string) []ScoredKey {
+	partial = t.normalize(partial)
+	var res []ScoredKey
+
+	query := []rune(partial)
+	fuzzycollect(t.Root(), nil, query, fuzzymatch{}, nil, func(key []rune, _ *Node, fm fuzzymatch) bool {
+		res = append(res, ScoredKey{Key: string(key), Score: fuzzyscore(len(query), len(key), fm)})
+		return true
+	})
+
+	sort.SliceStable(res, func(i, j int) bool { return res[i].Score > res[j].Score })
+	return res
+}
+
+func fuzzyscore(querylen, keylen int, fm fuzzymatch) float64 {
+	if querylen == 0 {
+		return 0
+	}
+
+	contiguity := 1.0
+	if querylen > 1 {
+		contiguity = float64(fm.adjacent) / float64(querylen-1)
+	}
+
+	return contiguity + 1/float64(1+fm.first) + float64(querylen)/float64(keylen)
+}
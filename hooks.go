@@ -0,0 +1,25 @@
+package trie
+
+// removed calls the OnRemove function, if any, with every key
+// of gone, after the keys have been removed from the trie.
+func (t *Trie) removed(gone []KV) {
+	if t.onremove == nil {
+		return
+	}
+
+	for _, kv := range gone {
+		t.onremove(kv.Key, kv.Meta)
+	}
+}
+
+// kvs returns the keys below node, whose path is pre, together
+// with their metas, in lexicographic order.
+func kvs(node *Node, pre []rune) []KV {
+	var res []KV
+	collectfunc(node, pre, func(key []rune, n *Node) bool {
+		res = append(res, KV{Key: string(key), Meta: n.Meta()})
+		return true
+	})
+
+	return res
+}
@@ -0,0 +1,31 @@
+//go:build go1.23
+
+package trie
+
+import "iter"
+
+// All returns an iterator over all keys in the trie and their
+// meta, in lexicographic order.
+func (t *Trie) All() iter.Seq2[string, interface{}] {
+	return func(yield func(string, interface{}) bool) {
+		collectfunc(t.Root(), nil, func(key []rune, n *Node) bool {
+			return yield(string(key), n.Meta())
+		})
+	}
+}
+
+// Prefix returns an iterator over the keys starting with pre,
+// in lexicographic order.
+func (t *Trie) Prefix(pre string) iter.Seq[string] {
+	pre = t.normalize(pre)
+	return func(yield func(string) bool) {
+		node := t.nodeAtPath(pre)
+		if node == nil {
+			return
+		}
+
+		collectfunc(node, []rune(pre), func(key []rune, _ *Node) bool {
+			return yield(string(key))
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package trie
+
+import "errors"
+
+// ErrModified is reported by an Iterator whose trie
+// has been modified during the iteration.
+var ErrModified = errors.New("trie modified during iteration")
+
+// Iterator walks the keys of a trie in lexicographic order. It
+// keeps a stack of the nodes on the path to the current key, so its
+// memory is proportional to the length of the longest key.
+//
+// Adding or removing keys while iterating ends the iteration,
+// after which Err returns ErrModified.
+type Iterator struct {
+	t       *Trie
+	version uint64
+	root    *Node
+	prefix  []rune
+	stack   []frame
+	pre     []rune
+	reverse bool
+	err     error
+}
+
+type frame struct {
+	node  *Node
+	runes []rune
+	i     int
+}
+
+// Iterator returns an Iterator over the keys starting with prefix.
+func (t *Trie) Iterator(prefix string) *Iterator {
+	return t.iterator(prefix, false)
+}
+
+// ReverseIterator returns an Iterator over the keys starting with
+// prefix in reverse lexicographic order.
+func (t *Trie) ReverseIterator(prefix string) *Iterator {
+	return t.iterator(prefix, true)
+}
+
+// KeysReverse returns all the keys currently stored in the trie
+// in reverse lexicographic order.
+func (t *Trie) KeysReverse() []string {
+	return t.PrefixSearchReverse("")
+}
+
+// PrefixSearchReverse is like PrefixSearch but returns the keys
+// in reverse lexicographic order.
+func (t *Trie) PrefixSearchReverse(pre string) []string {
+	var keys []string
+
+	it := t.ReverseIterator(pre)
+	for key, _, ok := it.Next(); ok; key, _, ok = it.Next() {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+func (t *Trie) iterator(prefix string, reverse bool) *Iterator {
+	prefix = t.normalize(prefix)
+	it := &Iterator{
+		t:       t,
+		version: t.version,
+		root:    t.nodeAtPath(prefix),
+		prefix:  []rune(prefix),
+		reverse: reverse,
+	}
+
+	it.reset()
+	return it
+}
+
+// Seek positions the iterator so that the following call to Next
+// returns the first key >= key, or the first key <= key going
+// backwards. Seeking also resumes an iteration which was ended by
+// a modification of the trie.
+//
+// The descent follows the runes of key and, where there is no child
+// for a rune, continues with the next larger child (next smaller
+// going backwards) from where Next takes the leftmost path.
+func (it *Iterator) Seek(key string) {
+	target := []rune(it.t.normalize(key))
+
+	// The node of the prefix may have been removed, or replaced,
+	// since the iterator was created.
+	it.version, it.err = it.t.version, nil
+	it.root = it.t.nodeAtPath(string(it.prefix))
+	it.reset()
+	if len(it.stack) == 0 {
+		return
+	}
+
+	if !hasprefix(target, it.prefix) {
+		if (string(target) < string(it.prefix)) != it.reverse {
+			return
+		}
+
+		// Every key is beyond the target.
+		it.stack = nil
+		return
+	}
+
+	for d := len(it.prefix); ; d++ {
+		r := rune(nul)
+		if d < len(target) {
+			r = target[d]
+		}
+
+		f := &it.stack[len(it.stack)-1]
+		for f.i < len(f.runes) && it.before(f.runes[f.i], r) {
+			f.i++
+		}
+
+		if r == nul || f.i == len(f.runes) || f.runes[f.i] != r {
+			return
+		}
+
+		n := f.node.children[r]
+		f.i++
+		it.pre = append(it.pre, r)
+		it.push(n)
+	}
+}
+
+// before reports whether a is visited before b.
+func (it *Iterator) before(a, b rune) bool {
+	if it.reverse {
+		return a > b
+	}
+
+	return a < b
+}
+
+func (it *Iterator) reset() {
+	it.stack = it.stack[:0]
+	it.pre = append(it.pre[:0], it.prefix...)
+	if it.root != nil {
+		it.push(it.root)
+	}
+}
+
+// Next advances the iterator and returns the next key and its meta.
+// ok is false once there are no more keys.
+func (it *Iterator) Next() (key string, meta interface{}, ok bool) {
+	if it.err == nil && it.version != it.t.version {
+		it.err = ErrModified
+		it.stack = nil
+	}
+
+	for len(it.stack) > 0 {
+		f := &it.stack[len(it.stack)-1]
+		if f.i == len(f.runes) {
+			it.pop()
+			continue
+		}
+
+		r := f.runes[f.i]
+		f.i++
+
+		n := f.node.children[r]
+		if n.term {
+			if n.alive() {
+				return string(it.pre), n.Meta(), true
+			}
+			continue
+		}
+
+		it.pre = append(it.pre, r)
+		it.push(n)
+	}
+
+	return "", nil, false
+}
+
+// Err returns the error which ended the iteration, if any.
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// push puts n on the stack. Going backwards the runes are visited
+// in descending order, so the terminator of a node comes last.
+func (it *Iterator) push(n *Node) {
+	rs := sortedrunes(n.children)
+	if it.reverse {
+		for i, j := 0, len(rs)-1; i < j; i, j = i+1, j-1 {
+			rs[i], rs[j] = rs[j], rs[i]
+		}
+	}
+
+	it.stack = append(it.stack, frame{node: n, runes: rs})
+}
+
+// pop drops the top of the stack along with the rune which led to it.
+func (it *Iterator) pop() {
+	it.stack = it.stack[:len(it.stack)-1]
+	if len(it.stack) > 0 {
+		it.pre = it.pre[:len(it.pre)-1]
+	}
+}
+
+func hasprefix(rs, pre []rune) bool {
+	if len(pre) > len(rs) {
+		return false
+	}
+
+	for i, r := range pre {
+		if rs[i] != r {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,68 @@
+package trie
+
+import (
+	"reflect"
+	"testing"
+)
+
+func iterate(it *Iterator) []string {
+	var keys []string
+	for key, _, ok := it.Next(); ok; key, _, ok = it.Next() {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+func TestIteratorSeekAfterRemove(t *testing.T) {
+	trie := NewTrie()
+	for _, key := range []string{"ab", "abc", "b"} {
+		trie.Add(key, nil)
+	}
+
+	it := trie.Iterator("ab")
+	trie.RemoveAll("ab")
+	it.Seek("")
+
+	if keys := iterate(it); len(keys) != 0 {
+		t.Errorf("expected no keys after RemoveAll, got %v", keys)
+	}
+
+	trie.Add("abd", nil)
+	it.Seek("")
+	if keys := iterate(it); !reflect.DeepEqual(keys, []string{"abd"}) {
+		t.Errorf("expected [abd] after re-adding, got %v", keys)
+	}
+}
+
+func TestIteratorSeekAfterClear(t *testing.T) {
+	trie := NewTrie()
+	trie.Add("a", nil)
+	trie.Add("b", nil)
+
+	it := trie.Iterator("")
+	trie.Clear()
+	trie.Add("c", nil)
+	it.Seek("")
+
+	if keys := iterate(it); !reflect.DeepEqual(keys, []string{"c"}) {
+		t.Errorf("expected [c] after Clear, got %v", keys)
+	}
+}
+
+func TestKeysReverse(t *testing.T) {
+	trie := NewTrie()
+	for _, key := range []string{"a", "ab", "b", "", "abc", "ba"} {
+		trie.Add(key, nil)
+	}
+
+	if keys := trie.KeysReverse(); !reflect.DeepEqual(keys, []string{"ba", "b", "abc", "ab", "a", ""}) {
+		t.Errorf("expected [ba b abc ab a ], got %q", keys)
+	}
+	if keys := trie.PrefixSearchReverse("a"); !reflect.DeepEqual(keys, []string{"abc", "ab", "a"}) {
+		t.Errorf("expected [abc ab a], got %q", keys)
+	}
+	if keys := iterate(trie.ReverseIterator("b")); !reflect.DeepEqual(keys, []string{"ba", "b"}) {
+		t.Errorf("expected [ba b], got %q", keys)
+	}
+}
@@ -0,0 +1,78 @@
+package trie
+
+// QWERTY maps every key of a QWERTY keyboard
+// to the keys physically adjacent to it.
+var QWERTY = layout(
+	"1234567890",
+	"qwertyuiop",
+	"asdfghjkl",
+	"zxcvbnm",
+)
+
+// layout builds the adjacency of a keyboard from its rows, where
+// every row is shifted to the right by about half a key compared to
+// the row above, so the key at position i touches the keys at i and
+// i+1 of the row above and at i-1 and i of the row below.
+func layout(rows ...string) map[rune][]rune {
+	grid := make([][]rune, len(rows))
+	for i, row := range rows {
+		grid[i] = []rune(row)
+	}
+
+	at := func(row, col int) (rune, bool) {
+		if row < 0 || row >= len(grid) || col < 0 || col >= len(grid[row]) {
+			return 0, false
+		}
+		return grid[row][col], true
+	}
+
+	adj := make(map[rune][]rune)
+	for i, row := range grid {
+		for j, r := range row {
+			for _, p := range [][2]int{
+				{i, j - 1}, {i, j + 1},
+				{i - 1, j}, {i - 1, j + 1},
+				{i + 1, j - 1}, {i + 1, j},
+			} {
+				if n, ok := at(p[0], p[1]); ok {
+					adj[r] = append(adj[r], n)
+				}
+			}
+		}
+	}
+
+	return adj
+}
+
+// SearchAdjacent returns all keys of the same length as word which
+// differ from it in at most maxSubs runes, where every differing
+// rune is adjacent in layout to the rune of word it replaces.
+func (t *Trie) SearchAdjacent(word string, maxSubs int, layout map[rune][]rune) []string {
+	word = t.normalize(word)
+	var keys []string
+	adjacentcollect(t.Root(), nil, []rune(word), maxSubs, layout, &keys)
+	return keys
+}
+
+func adjacentcollect(node *Node, pre, rest []rune, subs int, layout map[rune][]rune, keys *[]string) {
+	if len(rest) == 0 {
+		if n, ok := node.children[nul]; ok && n.alive() {
+			*keys = append(*keys, string(pre))
+		}
+		return
+	}
+
+	if n, ok := node.children[rest[0]]; ok && !n.term {
+		adjacentcollect(n, append(pre, rest[0]), rest[1:], subs, layout, keys)
+	}
+
+	if subs <= 0 {
+		return
+	}
+
+	for _, r := range layout[rest[0]] {
+		if n, ok := node.children[r]; ok && !n.term {
+			adjacentcollect(n, append(pre, r), rest[1:], subs-1, layout, keys)
+		}
+	}
+}
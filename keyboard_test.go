@@ -0,0 +1,42 @@
+package trie
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestQWERTY(t *testing.T) {
+	adj := append([]rune(nil), QWERTY['g']...)
+	sort.Slice(adj, func(i, j int) bool { return adj[i] < adj[j] })
+	if string(adj) != "bfhtvy" {
+		t.Errorf("expected g to touch bfhtvy, got %s", string(adj))
+	}
+
+	if adj := QWERTY['q']; !reflect.DeepEqual(adj, []rune{'w', '1', '2', 'a'}) {
+		t.Errorf("expected q to touch w12a, got %s", string(adj))
+	}
+}
+
+func TestSearchAdjacent(t *testing.T) {
+	trie := NewTrie()
+	for _, key := range []string{"hello", "jello", "hellp", "yellp", "hell"} {
+		trie.Add(key, nil)
+	}
+
+	search := func(word string, maxSubs int) []string {
+		keys := trie.SearchAdjacent(word, maxSubs, QWERTY)
+		sort.Strings(keys)
+		return keys
+	}
+
+	if keys := search("gello", 1); !reflect.DeepEqual(keys, []string{"hello"}) {
+		t.Errorf("expected [hello], got %v", keys)
+	}
+	if keys := search("hello", 0); !reflect.DeepEqual(keys, []string{"hello"}) {
+		t.Errorf("expected [hello] without substitutions, got %v", keys)
+	}
+	if keys := search("gello", 2); !reflect.DeepEqual(keys, []string{"hello", "hellp", "yellp"}) {
+		t.Errorf("expected [hello hellp yellp], got %v", keys)
+	}
+}
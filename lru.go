@@ -0,0 +1,43 @@
+package trie
+
+// use moves the terminator node n to the front of the
+// list of recently used keys, adding it if needed.
+func (t *Trie) use(n *Node) {
+	if t.lru == nil {
+		return
+	}
+
+	e := n.entry()
+	if e.elem == nil {
+		e.elem = t.lru.PushFront(n)
+		return
+	}
+
+	t.lru.MoveToFront(e.elem)
+}
+
+// unuse drops the terminator node n from the list
+// of recently used keys.
+func (t *Trie) unuse(n *Node) {
+	if t.lru == nil || n.ext == nil || n.ext.elem == nil {
+		return
+	}
+
+	t.lru.Remove(n.ext.elem)
+	n.ext.elem = nil
+}
+
+// evict removes the least recently used key from the trie.
+func (t *Trie) evict() {
+	n := t.lru.Back().Value.(*Node)
+	key, meta := n.Key(), n.Meta()
+
+	if t.counted {
+		t.total -= n.count()
+	}
+	t.delete(n, key)
+
+	if t.onevict != nil {
+		t.onevict(key, meta)
+	}
+}
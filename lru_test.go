@@ -0,0 +1,49 @@
+package trie
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMaxEntries(t *testing.T) {
+	var evicted []string
+	trie := NewTrie(WithMaxEntries(2), OnEvict(func(key string, _ interface{}) {
+		evicted = append(evicted, key)
+	}))
+
+	trie.Add("ab", 1)
+	trie.Add("abc", 2)
+	trie.Get("ab")
+	trie.Add("abd", 3)
+
+	if !reflect.DeepEqual(evicted, []string{"abc"}) {
+		t.Errorf("expected abc to be evicted, got %v", evicted)
+	}
+	if keys := trie.Keys(); !reflect.DeepEqual(keys, []string{"ab", "abd"}) {
+		t.Errorf("expected [ab abd], got %v", keys)
+	}
+	if trie.Len() != 2 {
+		t.Errorf("expected 2 keys, got %d", trie.Len())
+	}
+}
+
+func TestMaxEntriesLookups(t *testing.T) {
+	lookups := map[string]func(trie *Trie, key string){
+		"Find":     func(trie *Trie, key string) { trie.Find(key) },
+		"Get":      func(trie *Trie, key string) { trie.Get(key) },
+		"GetBytes": func(trie *Trie, key string) { trie.GetBytes([]byte(key)) },
+		"FindAll":  func(trie *Trie, key string) { trie.FindAll([]string{key}) },
+	}
+
+	for name, lookup := range lookups {
+		trie := NewTrie(WithMaxEntries(2))
+		trie.Add("a", nil)
+		trie.Add("b", nil)
+		lookup(trie, "a")
+		trie.Add("c", nil)
+
+		if keys := trie.Keys(); !reflect.DeepEqual(keys, []string{"a", "c"}) {
+			t.Errorf("%s: expected [a c], got %v", name, keys)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package trie
+
+// Anagrams returns all keys which are anagrams of word, including
+// word itself if it is stored. Subtrees lacking any of the letters
+// still to be placed are pruned using the node masks.
+func (t *Trie) Anagrams(word string) []string {
+	word = t.normalize(word)
+	var keys []string
+
+	rs := []rune(word)
+	counts := make(map[rune]int, len(rs))
+	for _, r := range rs {
+		counts[r]++
+	}
+
+	anagramcollect(t.Root(), nil, counts, len(rs), maskruneslice(rs), &keys)
+	return keys
+}
+
+// anagramcollect descends only into children whose rune is still
+// available in counts, so the traversal never goes deeper than the
+// word itself. m is the mask of the letters still available; the
+// masks can't count repeated letters, the counts do.
+func anagramcollect(node *Node, pre []rune, counts map[rune]int, left int, m uint64, keys *[]string) {
+	children := node.children
+	for _, r := range sortedrunes(children) {
+		n := children[r]
+		if n.term {
+			if left == 0 && n.alive() {
+				*keys = append(*keys, string(pre))
+			}
+			continue
+		}
+
+		if left == 0 || counts[r] == 0 || m&^n.Mask() != 0 {
+			continue
+		}
+
+		nm := m
+		if counts[r]--; counts[r] == 0 {
+			nm &^= maskrune(r)
+		}
+
+		anagramcollect(n, append(pre, r), counts, left-1, nm, keys)
+		counts[r]++
+	}
+}
+
+// ContainingLetters returns all keys containing every letter of
+// letters, in any order and position. A letter repeated in letters
+// has to be repeated as often in the key.
+func (t *Trie) ContainingLetters(letters string) []string {
+	letters = t.normalize(letters)
+	var keys []string
+
+	rs := []rune(letters)
+	counts := make(map[rune]int, len(rs))
+	for _, r := range rs {
+		counts[r]++
+	}
+
+	letterscollect(t.Root(), nil, counts, len(rs), maskruneslice(rs), &keys)
+	return keys
+}
+
+// letterscollect prunes every subtree whose mask lacks one of the
+// letters still missing, and takes the whole subtree once no letter
+// is missing anymore.
+func letterscollect(node *Node, pre []rune, counts map[rune]int, left int, m uint64, keys *[]string) {
+	if left == 0 {
+		collect(node, pre, keys, 0)
+		return
+	}
+
+	children := node.children
+	for _, r := range sortedrunes(children) {
+		n := children[r]
+		if n.term || m&^n.Mask() != 0 {
+			continue
+		}
+
+		if counts[r] == 0 {
+			letterscollect(n, append(pre, r), counts, left, m, keys)
+			continue
+		}
+
+		nm := m
+		if counts[r]--; counts[r] == 0 {
+			nm &^= maskrune(r)
+		}
+
+		letterscollect(n, append(pre, r), counts, left-1, nm, keys)
+		counts[r]++
+	}
+}
@@ -0,0 +1,65 @@
+package trie
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAnagrams(t *testing.T) {
+	trie := NewTrie()
+	for _, key := range []string{"listen", "silent", "enlist", "list", "listens"} {
+		trie.Add(key, nil)
+	}
+
+	if keys := trie.Anagrams("inlets"); !reflect.DeepEqual(keys, []string{"enlist", "listen", "silent"}) {
+		t.Errorf("expected [enlist listen silent], got %v", keys)
+	}
+}
+
+func TestAnagramsRepeatedLetters(t *testing.T) {
+	trie := NewTrie()
+	for _, key := range []string{"letter", "relett", "leter", "lettter", "retlet"} {
+		trie.Add(key, nil)
+	}
+
+	if keys := trie.Anagrams("tetrel"); !reflect.DeepEqual(keys, []string{"letter", "relett", "retlet"}) {
+		t.Errorf("expected [letter relett retlet], got %v", keys)
+	}
+}
+
+func TestContainingLetters(t *testing.T) {
+	trie := NewTrie()
+	for _, key := range []string{"apple", "plea", "paper", "leap", "pal"} {
+		trie.Add(key, nil)
+	}
+
+	if keys := trie.ContainingLetters("lpa"); !reflect.DeepEqual(keys, []string{"apple", "leap", "pal", "plea"}) {
+		t.Errorf("expected [apple leap pal plea], got %v", keys)
+	}
+	if keys := trie.ContainingLetters("pp"); !reflect.DeepEqual(keys, []string{"apple", "paper"}) {
+		t.Errorf("expected [apple paper] for a repeated letter, got %v", keys)
+	}
+	if keys := trie.ContainingLetters("ppp"); len(keys) != 0 {
+		t.Errorf("expected no keys, got %v", keys)
+	}
+}
+
+func TestContainingLettersOutsideMask(t *testing.T) {
+	trie := NewTrie()
+	for _, key := range []string{"café", "cafe", "a1b2", "ab", "Étage"} {
+		trie.Add(key, nil)
+	}
+
+	if keys := trie.ContainingLetters("é"); !reflect.DeepEqual(keys, []string{"café"}) {
+		t.Errorf("expected [café], got %v", keys)
+	}
+	if keys := trie.ContainingLetters("2a"); !reflect.DeepEqual(keys, []string{"a1b2"}) {
+		t.Errorf("expected [a1b2], got %v", keys)
+	}
+	if keys := trie.ContainingLetters("ÉÉ"); len(keys) != 0 {
+		t.Errorf("expected no keys, got %v", keys)
+	}
+	if keys := trie.ContainingLetters("É"); !reflect.DeepEqual(keys, []string{"Étage"}) {
+		t.Errorf("expected [Étage], got %v", keys)
+	}
+}
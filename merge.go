@@ -0,0 +1,133 @@
+package trie
+
+// Merge adds every key of other to t. For a key stored in both tries
+// the meta becomes the result of resolve, called with the key, the
+// meta in t and the meta in other. With Counted the counts of such
+// keys add up. other is left untouched.
+//
+// Both tries are walked in lockstep, and every branch which only
+// exists in other is copied into t as a whole. If t normalizes keys
+// differently from other, or other has keys marked deleted, the keys
+// of other are added one by one.
+func (t *Trie) Merge(other *Trie, resolve func(key string, ours, theirs interface{}) interface{}) {
+	if t.norm != nil || (t.fold && !other.fold) || other.tombs > 0 {
+		t.mergekeys(other, resolve)
+		return
+	}
+
+	var added []KV
+	reweigh := t.weight != nil || other.weight != nil
+	t.mergenode(t.Root(), other.Root(), nil, resolve, reweigh, &added)
+	t.version++
+
+	if t.onadd != nil {
+		for _, kv := range added {
+			t.onadd(kv.Key, kv.Meta)
+		}
+	}
+
+	for t.lru != nil && t.size > t.max {
+		t.evict()
+	}
+}
+
+// mergenode merges the nodes below src into dst, which is the node
+// of the path pre, and records the keys added in added.
+func (t *Trie) mergenode(dst, src *Node, pre []rune, resolve func(key string, ours, theirs interface{}) interface{}, reweigh bool, added *[]KV) {
+	for _, r := range sortedrunes(src.children) {
+		s := src.children[r]
+		d, ok := dst.children[r]
+		if ok && d.term && !d.alive() {
+			// A key marked deleted in t is copied like a new one.
+			delete(dst.children, r)
+			t.tombs--
+			ok = false
+		}
+
+		switch {
+		case ok && s.term:
+			key := string(pre)
+			meta := resolve(key, d.meta, s.meta)
+			t.unindex(key, d.meta)
+			d.meta = meta
+			t.index(key, meta)
+
+			if t.counted {
+				d.ext.count += s.count()
+				t.total += s.count()
+			}
+
+			if t.weight != nil {
+				d.best = t.weight(meta)
+			}
+		case ok:
+			t.mergenode(d, s, append(pre, r), resolve, reweigh, added)
+		default:
+			npre := pre
+			if !s.term {
+				npre = append(pre, r)
+			}
+
+			c := t.clonenode(s, dst, npre, nil, nil)
+			dst.children[r] = c
+			if c.term {
+				t.adopt(c, string(pre), reweigh, added)
+				continue
+			}
+
+			collectfunc(c, npre, func(key []rune, n *Node) bool {
+				t.adopt(n, string(key), reweigh, added)
+				return true
+			})
+		}
+	}
+
+	dst.recalculateMask()
+	dst.recalculateBest()
+}
+
+// adopt updates the state of t for the terminator node n of key,
+// which has just been copied into t from another trie.
+func (t *Trie) adopt(n *Node, key string, reweigh bool, added *[]KV) {
+	t.size++
+
+	if t.counted {
+		c := n.count()
+		n.entry().count = c
+		t.total += c
+	}
+
+	if reweigh {
+		t.reweigh(n)
+	}
+
+	if t.suffix != nil {
+		t.suffix.Add(reverse(key), nil)
+	}
+
+	t.use(n)
+	*added = append(*added, KV{Key: key, Meta: n.meta})
+}
+
+// mergekeys merges other into t key by key.
+func (t *Trie) mergekeys(other *Trie, resolve func(key string, ours, theirs interface{}) interface{}) {
+	collectfunc(other.Root(), nil, func(key []rune, s *Node) bool {
+		k := string(key)
+		node, added := t.put(k, func(old interface{}, exists bool) interface{} {
+			if !exists {
+				return s.meta
+			}
+			return resolve(k, old, s.meta)
+		})
+
+		if t.counted {
+			c := s.count()
+			if added {
+				c--
+			}
+			node.ext.count += c
+			t.total += c
+		}
+		return true
+	})
+}
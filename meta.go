@@ -0,0 +1,280 @@
+package trie
+
+import (
+	"reflect"
+	"sort"
+)
+
+// FindByMeta returns the keys whose meta satisfies pred, in
+// lexicographic order. Every key is passed to pred, including
+// those with a nil meta.
+func (t *Trie) FindByMeta(pred func(meta interface{}) bool) []string {
+	var keys []string
+	collectfunc(t.Root(), nil, func(key []rune, n *Node) bool {
+		if pred(n.Meta()) {
+			keys = append(keys, string(key))
+		}
+		return true
+	})
+
+	return keys
+}
+
+// FindFirstByMeta is like FindByMeta but stops at the first key
+// whose meta satisfies pred. ok is false if there is none.
+func (t *Trie) FindFirstByMeta(pred func(meta interface{}) bool) (key string, ok bool) {
+	collectfunc(t.Root(), nil, func(k []rune, n *Node) bool {
+		if pred(n.Meta()) {
+			key, ok = string(k), true
+		}
+		return !ok
+	})
+
+	return key, ok
+}
+
+// AppendMeta adds meta as one more value of key, adding key if it
+// isn't stored yet, and returns the number of values of key. The
+// first value of a key is its meta as returned by Get and Find, and
+// adding the key again with Add replaces all of its values.
+func (t *Trie) AppendMeta(key string, meta interface{}) int {
+	var exists bool
+	node, _ := t.put(key, func(old interface{}, ok bool) interface{} {
+		if exists = ok; ok {
+			return old
+		}
+		return meta
+	})
+
+	if !exists {
+		return 1
+	}
+
+	e := node.entry()
+	e.more = append(e.more, meta)
+	return len(e.more) + 1
+}
+
+// Metas returns all values of key, or nil if key is not stored.
+func (t *Trie) Metas(key string) []interface{} {
+	node := t.live(t.terminal(t.normalize(key)))
+	if node == nil {
+		return nil
+	}
+
+	metas := []interface{}{node.Meta()}
+	if node.ext != nil {
+		metas = append(metas, node.ext.more...)
+	}
+
+	return metas
+}
+
+// RemoveMeta removes the first value of key which equals meta, and
+// removes key itself once its last value is gone. The values are
+// compared with ==, so they have to be comparable. It reports whether
+// a value was removed.
+func (t *Trie) RemoveMeta(key string, meta interface{}) bool {
+	key = t.normalize(key)
+	node := t.live(t.terminal(key))
+	if node == nil {
+		return false
+	}
+
+	var more []interface{}
+	if node.ext != nil {
+		more = node.ext.more
+	}
+
+	if node.Meta() == meta {
+		if len(more) == 0 {
+			return t.Remove(key)
+		}
+
+		t.unindex(key, node.meta)
+		node.meta = more[0]
+		node.ext.more = more[1:]
+		t.index(key, node.meta)
+		if t.weight != nil {
+			t.reweigh(node)
+		}
+		return true
+	}
+
+	for i, m := range more {
+		if m == meta {
+			node.ext.more = append(more[:i:i], more[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// CompareAndSwapMeta replaces the meta of key with new, but only if
+// its current meta equals old. The metas are compared with ==, so they
+// have to be comparable. It reports whether the meta was replaced,
+// which it never is for a key that isn't stored.
+//
+// The check and the update happen in a single call, so guarding the
+// trie with a lock is enough to make the swap atomic.
+func (t *Trie) CompareAndSwapMeta(key string, old, new interface{}) bool {
+	return t.CompareAndSwapMetaFunc(key, old, new, func(a, b interface{}) bool {
+		return a == b
+	})
+}
+
+// CompareAndSwapMetaFunc is like CompareAndSwapMeta but compares the
+// current meta of key with old using equal.
+func (t *Trie) CompareAndSwapMetaFunc(key string, old, new interface{}, equal func(a, b interface{}) bool) bool {
+	key = t.normalize(key)
+	node := t.live(t.terminal(key))
+	if node == nil || !equal(node.Meta(), old) {
+		return false
+	}
+
+	t.unindex(key, node.meta)
+	node.meta = new
+	t.index(key, new)
+	if t.weight != nil {
+		t.reweigh(node)
+	}
+
+	return true
+}
+
+// KeysForMeta returns the keys whose meta equals meta, in
+// lexicographic order. It needs the trie to be created with
+// WithMetaIndex, and returns nil otherwise.
+func (t *Trie) KeysForMeta(meta interface{}) []string {
+	if t.metas == nil || !indexable(meta) {
+		return nil
+	}
+
+	keys := make([]string, 0, len(t.metas[meta]))
+	for key := range t.metas[meta] {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// index records that the normalized key has meta.
+func (t *Trie) index(key string, meta interface{}) {
+	if t.metas == nil || !indexable(meta) {
+		return
+	}
+
+	keys, ok := t.metas[meta]
+	if !ok {
+		keys = make(map[string]struct{})
+		t.metas[meta] = keys
+	}
+	keys[key] = struct{}{}
+}
+
+// unindex forgets that the normalized key has meta.
+func (t *Trie) unindex(key string, meta interface{}) {
+	if t.metas == nil || !indexable(meta) {
+		return
+	}
+
+	keys := t.metas[meta]
+	delete(keys, key)
+	if len(keys) == 0 {
+		delete(t.metas, meta)
+	}
+}
+
+// indexable reports whether meta can be used as a map key.
+func indexable(meta interface{}) bool {
+	return meta == nil || hashable(reflect.ValueOf(meta))
+}
+
+// hashable reports whether v can be hashed. Unlike the Comparable
+// method of its type it looks at the values held by interfaces,
+// which make comparable structs and arrays unhashable when they
+// hold a slice, a map or a func.
+func hashable(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Func:
+		return false
+	case reflect.Interface:
+		return v.IsNil() || hashable(v.Elem())
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !hashable(v.Field(i)) {
+				return false
+			}
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if !hashable(v.Index(i)) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// MapMeta replaces the meta of every key with the result of fn,
+// called with the key and its current meta, in lexicographic order.
+// The keys themselves are left untouched. Where fn returns the meta
+// unchanged nothing is allocated, apart from the key passed to fn.
+func (t *Trie) MapMeta(fn func(key string, meta interface{}) interface{}) {
+	t.mapmeta(t.Root(), make([]rune, 0, 32), fn)
+}
+
+// mapmeta does the work of MapMeta for the keys below node, whose
+// path is pre. The children are sorted into a buffer on the stack
+// rather than by sortedrunes, which would allocate for every node.
+func (t *Trie) mapmeta(node *Node, pre []rune, fn func(key string, meta interface{}) interface{}) {
+	var buf [8]rune
+	for _, r := range appendsorted(buf[:0], node.children) {
+		n := node.children[r]
+		if !n.term {
+			t.mapmeta(n, append(pre, r), fn)
+			continue
+		}
+
+		if !n.alive() {
+			continue
+		}
+
+		// Even an empty key would be allocated by string.
+		var k string
+		if len(pre) > 0 {
+			k = string(pre)
+		}
+
+		meta := fn(k, n.meta)
+		if indexable(meta) && meta == n.meta {
+			continue
+		}
+
+		t.unindex(k, n.meta)
+		n.meta = meta
+		t.index(k, meta)
+
+		if t.weight != nil {
+			t.reweigh(n)
+		}
+	}
+}
+
+// appendsorted appends the runes of children to rs, which has to be
+// empty, in ascending order. It only allocates if rs has no room.
+func appendsorted(rs []rune, children map[rune]*Node) []rune {
+	for r := range children {
+		i := len(rs)
+		rs = append(rs, r)
+		for ; i > 0 && rs[i-1] > r; i-- {
+			rs[i] = rs[i-1]
+		}
+		rs[i] = r
+	}
+
+	return rs
+}
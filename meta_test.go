@@ -0,0 +1,135 @@
+package trie
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestKeysForMeta(t *testing.T) {
+	trie := NewTrie(WithMetaIndex())
+	trie.Add("a", 1)
+	trie.Add("b", 1)
+	trie.Add("c", 2)
+	trie.SetMeta("b", 2)
+	trie.Remove("c")
+
+	if keys := trie.KeysForMeta(1); !reflect.DeepEqual(keys, []string{"a"}) {
+		t.Errorf("expected [a] for 1, got %v", keys)
+	}
+	if keys := trie.KeysForMeta(2); !reflect.DeepEqual(keys, []string{"b"}) {
+		t.Errorf("expected [b] for 2, got %v", keys)
+	}
+}
+
+func TestKeysForMetaUnhashable(t *testing.T) {
+	type holder struct{ X interface{} }
+
+	trie := NewTrie(WithMetaIndex())
+	trie.Add("slice", []int{1})
+	trie.Add("struct", holder{X: []int{1}})
+	trie.Add("array", [1]interface{}{map[string]int{}})
+	trie.Add("ok", holder{X: 1})
+
+	if keys := trie.KeysForMeta(holder{X: []int{1}}); keys != nil {
+		t.Errorf("expected nil for an unhashable meta, got %v", keys)
+	}
+	if keys := trie.KeysForMeta(holder{X: 1}); !reflect.DeepEqual(keys, []string{"ok"}) {
+		t.Errorf("expected [ok], got %v", keys)
+	}
+
+	trie.SetMeta("struct", holder{X: map[int]int{}})
+	trie.Remove("array")
+	if trie.Len() != 3 {
+		t.Errorf("expected 3 keys, got %d", trie.Len())
+	}
+}
+
+func TestMapMeta(t *testing.T) {
+	trie := NewTrie(WithMetaIndex())
+	trie.Add("foo", 1)
+	trie.Add("foobar", 2)
+	trie.Add("bar", 3)
+
+	var keys []string
+	trie.MapMeta(func(key string, meta interface{}) interface{} {
+		keys = append(keys, key)
+		return meta.(int) * 10
+	})
+
+	if !reflect.DeepEqual(keys, []string{"bar", "foo", "foobar"}) {
+		t.Errorf("expected fn to be called in lexicographic order, got %v", keys)
+	}
+	if m, _ := trie.Get("foobar"); m != 20 {
+		t.Errorf("expected 20 for foobar, got %v", m)
+	}
+	if keys := trie.KeysForMeta(30); !reflect.DeepEqual(keys, []string{"bar"}) {
+		t.Errorf("expected [bar] for 30, got %v", keys)
+	}
+	if trie.Len() != 3 {
+		t.Errorf("expected 3 keys, got %d", trie.Len())
+	}
+}
+
+func TestMapMetaUnchangedAllocs(t *testing.T) {
+	trie := NewTrie(WithMetaIndex())
+	for _, key := range []string{"", "foo", "foobar", "bar", "baz"} {
+		trie.Add(key, key)
+	}
+	trie.Add("slice", []int{1})
+
+	// Only the keys passed to fn are allocated, apart
+	// from the empty one.
+	same := func(_ string, meta interface{}) interface{} { return meta }
+	if allocs := testing.AllocsPerRun(100, func() { trie.MapMeta(same) }); allocs > 5 {
+		t.Errorf("expected at most 5 allocations for 5 keys, got %v", allocs)
+	}
+}
+
+func TestCompareAndSwapMeta(t *testing.T) {
+	trie := NewTrie()
+	trie.Add("foo", 1)
+
+	if trie.CompareAndSwapMeta("foo", 2, 3) {
+		t.Error("expected no swap for a different meta")
+	}
+	if !trie.CompareAndSwapMeta("foo", 1, 3) {
+		t.Error("expected the meta to be swapped")
+	}
+	if trie.CompareAndSwapMeta("bar", nil, 1) || trie.Contains("bar") {
+		t.Error("expected no swap, nor an insertion, for a missing key")
+	}
+}
+
+func TestCompareAndSwapMetaContention(t *testing.T) {
+	const workers, increments = 8, 200
+
+	var mu sync.Mutex
+	trie := NewTrie()
+	trie.Add("counter", 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < increments; {
+				mu.Lock()
+				old, _ := trie.Get("counter")
+				mu.Unlock()
+
+				// Another worker may swap in between.
+				mu.Lock()
+				if trie.CompareAndSwapMeta("counter", old, old.(int)+1) {
+					j++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if m, _ := trie.Get("counter"); m != workers*increments {
+		t.Errorf("expected %d, got %v", workers*increments, m)
+	}
+}
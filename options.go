@@ -0,0 +1,123 @@
+package trie
+
+import (
+	"container/list"
+	"time"
+)
+
+// Option configures a Trie created by NewTrie.
+type Option func(*Trie)
+
+// WithSuffixIndex makes the trie maintain a second trie of
+// reversed keys, which turns SuffixSearch into a prefix search.
+func WithSuffixIndex() Option {
+	return func(t *Trie) {
+		t.suffix = NewTrie()
+	}
+}
+
+// WithWeightFunc sets the function computing the weight of a key
+// from its meta, which is used to rank the results of TopK.
+func WithWeightFunc(weight func(meta interface{}) float64) Option {
+	return func(t *Trie) {
+		t.weight = weight
+	}
+}
+
+// WithRecency makes the trie remember when every key was last
+// added or touched, which is used to rank the results of
+// RecentWithPrefix.
+func WithRecency() Option {
+	return func(t *Trie) {
+		t.recency = true
+	}
+}
+
+// CaseInsensitive makes the trie ignore case. Keys are lower cased
+// when they are added, and so is every query, so the keys returned
+// by the trie are always in lower case.
+func CaseInsensitive() Option {
+	return func(t *Trie) {
+		t.fold = true
+	}
+}
+
+// WithNormalizer makes the trie pass every key, when it is added,
+// and every query through norm, so that logically equal strings
+// map to the same key. For Unicode normalization norm would be
+// e.g. norm.NFC.String from golang.org/x/text/unicode/norm.
+func WithNormalizer(norm func(string) string) Option {
+	return func(t *Trie) {
+		t.norm = norm
+	}
+}
+
+// Counted makes the trie a multiset. Adding a key which is already
+// stored increments its count, and removing it decrements the count,
+// so the key is only deleted once its count drops to zero.
+func Counted() Option {
+	return func(t *Trie) {
+		t.counted = true
+	}
+}
+
+// OnAdd sets a function called with every key added to the trie,
+// and its meta, once the key is in place. Adding a key which is
+// already stored doesn't call it.
+func OnAdd(fn func(key string, meta interface{})) Option {
+	return func(t *Trie) {
+		t.onadd = fn
+	}
+}
+
+// OnRemove sets a function called with every key removed from the
+// trie, and the meta it had, once the key is gone. With Counted it
+// is only called when the count of the key drops to zero.
+func OnRemove(fn func(key string, meta interface{})) Option {
+	return func(t *Trie) {
+		t.onremove = fn
+	}
+}
+
+// WithClock sets the function the trie reads the current time from,
+// for recency and expiry, instead of time.Now.
+func WithClock(now func() time.Time) Option {
+	return func(t *Trie) {
+		t.now = now
+	}
+}
+
+// WithMaxEntries bounds the trie to n keys. Adding a key to a full
+// trie evicts the least recently used key, where adding a key and
+// finding it with Find, Get, GetBytes or FindAll count as a use.
+// A value of n <= 0 means no bound.
+func WithMaxEntries(n int) Option {
+	return func(t *Trie) {
+		if n <= 0 {
+			t.max, t.lru = 0, nil
+			return
+		}
+
+		t.max, t.lru = n, list.New()
+	}
+}
+
+// OnEvict sets a function called with every key evicted because of
+// WithMaxEntries, and the meta it had, once the key is gone. OnRemove
+// is called for evicted keys as well.
+func OnEvict(fn func(key string, meta interface{})) Option {
+	return func(t *Trie) {
+		t.onevict = fn
+	}
+}
+
+// WithMetaIndex makes the trie maintain an index from metas to the
+// keys having them, which KeysForMeta looks up. Only the meta of a
+// key returned by Get is indexed, not the other values added with
+// AppendMeta, and metas which can't be map keys, like slices, maps,
+// or structs and arrays holding one, are left out of the index.
+func WithMetaIndex() Option {
+	return func(t *Trie) {
+		t.metas = make(map[interface{}]map[string]struct{})
+	}
+}
@@ -0,0 +1,38 @@
+package trie
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// nfc composes the only decomposed rune the tests use, standing
+// in for norm.NFC.String from golang.org/x/text/unicode/norm.
+var nfc = strings.NewReplacer("e\u0301", "\u00e9").Replace
+
+func TestWithNormalizer(t *testing.T) {
+	precomposed, decomposed := "caf\u00e9", "cafe\u0301"
+
+	trie := NewTrie(WithNormalizer(nfc))
+	trie.Add(precomposed, 1)
+	if _, err := trie.Find(decomposed); err != nil {
+		t.Errorf("expected the decomposed query to find the precomposed key: %v", err)
+	}
+
+	trie.Add(decomposed, 2)
+	if trie.Len() != 1 {
+		t.Errorf("expected both forms to be the same key, got %d keys", trie.Len())
+	}
+	if keys := trie.PrefixSearch("cafe\u0301"); !reflect.DeepEqual(keys, []string{precomposed}) {
+		t.Errorf("expected [%s], got %q", precomposed, keys)
+	}
+
+	other := NewTrie(WithNormalizer(nfc))
+	other.Add(decomposed, 1)
+	if _, err := other.Find(precomposed); err != nil {
+		t.Errorf("expected the precomposed query to find the decomposed key: %v", err)
+	}
+	if !other.Remove(precomposed) || other.Len() != 0 {
+		t.Errorf("expected the key to be removed, got %v", other.Keys())
+	}
+}
@@ -0,0 +1,235 @@
+package trie
+
+import (
+	"regexp"
+	"regexp/syntax"
+)
+
+const (
+	wildcardOne  = '?'
+	wildcardMany = '*'
+	escape       = '\\'
+)
+
+type token struct {
+	val  rune
+	one  bool
+	many bool
+}
+
+// Match returns all keys matching pattern in lexicographic order,
+// where every ? in the pattern matches exactly one rune. A literal ?
+// is written as \?, and a literal backslash as \\.
+func (t *Trie) Match(pattern string) []string {
+	pattern = t.normalize(pattern)
+	var keys []string
+	matchcollect(t.Root(), nil, tokenize(pattern, false), &keys)
+	return keys
+}
+
+// Glob returns all keys matching pattern in lexicographic order,
+// where every ? in the pattern matches exactly one rune and every *
+// matches zero or more runes. Both can be escaped with a backslash.
+//
+// The pattern is matched by carrying the set of reachable pattern
+// positions down the trie, so every node is visited at most once
+// regardless of how many stars the pattern contains.
+func (t *Trie) Glob(pattern string) []string {
+	pattern = t.normalize(pattern)
+	var keys []string
+
+	toks := tokenize(pattern, true)
+	states := make([]bool, len(toks)+1)
+	states[0] = true
+	globclosure(toks, states)
+
+	globcollect(t.Root(), nil, toks, states, &keys)
+	return keys
+}
+
+// MatchRegexp returns all keys matching re in lexicographic order.
+// The whole key has to match, as if re was surrounded by ^ and $.
+// Only the branch of the trie below the literal prefix of re is
+// visited.
+func (t *Trie) MatchRegexp(re *regexp.Regexp) []string {
+	var keys []string
+
+	full := regexp.MustCompile(`^(?:` + re.String() + `)$`)
+	pre := literalprefix(re)
+
+	node := t.nodeAtPath(pre)
+	if node == nil {
+		return keys
+	}
+
+	regexpcollect(node, []rune(pre), full, &keys)
+	return keys
+}
+
+func tokenize(pattern string, glob bool) []token {
+	var (
+		toks    []token
+		escaped bool
+	)
+
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			toks = append(toks, token{val: r})
+			escaped = false
+		case r == escape:
+			escaped = true
+		case r == wildcardOne:
+			toks = append(toks, token{one: true})
+		case glob && r == wildcardMany:
+			if len(toks) > 0 && toks[len(toks)-1].many {
+				continue
+			}
+			toks = append(toks, token{many: true})
+		default:
+			toks = append(toks, token{val: r})
+		}
+	}
+
+	// A trailing backslash has nothing to escape and is
+	// taken literally.
+	if escaped {
+		toks = append(toks, token{val: escape})
+	}
+
+	return toks
+}
+
+func matchcollect(node *Node, pre []rune, toks []token, keys *[]string) {
+	if len(toks) == 0 {
+		if n, ok := node.children[nul]; ok && n.alive() {
+			*keys = append(*keys, string(pre))
+		}
+		return
+	}
+
+	tok := toks[0]
+	if !tok.one {
+		n, ok := node.children[tok.val]
+		if ok && !n.term {
+			matchcollect(n, append(pre, tok.val), toks[1:], keys)
+		}
+		return
+	}
+
+	children := node.children
+	for _, r := range sortedrunes(children) {
+		n := children[r]
+		if n.term {
+			continue
+		}
+
+		matchcollect(n, append(pre, r), toks[1:], keys)
+	}
+}
+
+// globclosure activates the position after every active star,
+// since a star may match the empty string.
+func globclosure(toks []token, states []bool) {
+	for i, tok := range toks {
+		if states[i] && tok.many {
+			states[i+1] = true
+		}
+	}
+}
+
+// globrest reports whether the pattern ends in a star which is
+// active, which means every key below matches.
+func globrest(toks []token, states []bool) bool {
+	last := len(toks) - 1
+	return last >= 0 && toks[last].many && states[last]
+}
+
+func globcollect(node *Node, pre []rune, toks []token, states []bool, keys *[]string) {
+	if globrest(toks, states) {
+		collect(node, pre, keys, 0)
+		return
+	}
+
+	children := node.children
+	for _, r := range sortedrunes(children) {
+		n := children[r]
+		if n.term {
+			if states[len(toks)] && n.alive() {
+				*keys = append(*keys, string(pre))
+			}
+			continue
+		}
+
+		var (
+			next   = make([]bool, len(states))
+			active bool
+		)
+
+		for i, tok := range toks {
+			if !states[i] {
+				continue
+			}
+
+			switch {
+			case tok.many:
+				next[i] = true
+			case tok.one || tok.val == r:
+				next[i+1] = true
+			default:
+				continue
+			}
+			active = true
+		}
+
+		if !active {
+			continue
+		}
+
+		globclosure(toks, next)
+		globcollect(n, append(pre, r), toks, next, keys)
+	}
+}
+
+// literalprefix returns the literal string every match of re has to
+// begin with. Unlike re.LiteralPrefix it looks past a leading ^.
+func literalprefix(re *regexp.Regexp) string {
+	rx, err := syntax.Parse(re.String(), syntax.Perl)
+	if err != nil {
+		return ""
+	}
+
+	rx = rx.Simplify()
+	subs := []*syntax.Regexp{rx}
+	if rx.Op == syntax.OpConcat {
+		subs = rx.Sub
+	}
+
+	var pre []rune
+	for _, sub := range subs {
+		if sub.Op == syntax.OpBeginText {
+			continue
+		}
+		if sub.Op != syntax.OpLiteral || sub.Flags&syntax.FoldCase != 0 {
+			break
+		}
+		pre = append(pre, sub.Rune...)
+	}
+
+	return string(pre)
+}
+
+func regexpcollect(node *Node, pre []rune, re *regexp.Regexp, keys *[]string) {
+	children := node.children
+	for _, r := range sortedrunes(children) {
+		n := children[r]
+		if n.term {
+			if key := string(pre); n.alive() && re.MatchString(key) {
+				*keys = append(*keys, key)
+			}
+			continue
+		}
+
+		regexpcollect(n, append(pre, r), re, keys)
+	}
+}
@@ -0,0 +1,25 @@
+package trie
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestPatternOrder(t *testing.T) {
+	trie := NewTrie()
+	for _, key := range []string{"cut", "cot", "cat", "cats", "ct", "cit"} {
+		trie.Add(key, nil)
+	}
+
+	want := []string{"cat", "cit", "cot", "cut"}
+	if keys := trie.Match("c?t"); !reflect.DeepEqual(keys, want) {
+		t.Errorf("expected %v from Match, got %v", want, keys)
+	}
+	if keys := trie.Glob("c?t"); !reflect.DeepEqual(keys, want) {
+		t.Errorf("expected %v from Glob, got %v", want, keys)
+	}
+	if keys := trie.MatchRegexp(regexp.MustCompile(`c[a-z]t`)); !reflect.DeepEqual(keys, want) {
+		t.Errorf("expected %v from MatchRegexp, got %v", want, keys)
+	}
+}
@@ -0,0 +1,71 @@
+package trie
+
+import "sort"
+
+// Touch marks key as used now, if the trie was created with
+// WithRecency. It reports whether key is stored in the trie.
+func (t *Trie) Touch(key string) bool {
+	key = t.normalize(key)
+	node := t.live(t.terminal(key))
+	if node == nil {
+		return false
+	}
+
+	if t.recency {
+		node.entry().touched = t.clock().UnixNano()
+	}
+
+	return true
+}
+
+// RecentWithPrefix returns the n most recently added or touched
+// keys starting with prefix, most recent first. Keys touched at
+// the same time are ordered lexicographically. A value of n <= 0
+// means no limit. Without WithRecency the keys are returned in
+// lexicographic order.
+func (t *Trie) RecentWithPrefix(prefix string, n int) []string {
+	prefix = t.normalize(prefix)
+	node := t.nodeAtPath(prefix)
+	if node == nil {
+		return nil
+	}
+
+	var nodes []*Node
+	var keys []string
+	collectfunc(node, []rune(prefix), func(key []rune, n *Node) bool {
+		nodes = append(nodes, n)
+		keys = append(keys, string(key))
+		return true
+	})
+
+	sort.Stable(byRecency{nodes: nodes, keys: keys})
+	if n > 0 && n < len(keys) {
+		keys = keys[:n]
+	}
+
+	return keys
+}
+
+type byRecency struct {
+	nodes []*Node
+	keys  []string
+}
+
+func (b byRecency) Len() int { return len(b.keys) }
+
+func (b byRecency) Swap(i, j int) {
+	b.nodes[i], b.nodes[j] = b.nodes[j], b.nodes[i]
+	b.keys[i], b.keys[j] = b.keys[j], b.keys[i]
+}
+
+func (b byRecency) Less(i, j int) bool {
+	return touched(b.nodes[i]) > touched(b.nodes[j])
+}
+
+func touched(n *Node) int64 {
+	if n.ext == nil {
+		return 0
+	}
+
+	return n.ext.touched
+}
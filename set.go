@@ -0,0 +1,235 @@
+package trie
+
+import (
+	"reflect"
+	"sort"
+)
+
+// Union returns a new trie with the keys stored in t or other. The
+// keys stored in both keep the meta they have in t. The new trie is
+// configured like t, as described for Filter.
+func (t *Trie) Union(other *Trie) *Trie {
+	res := t.Clone()
+	res.Merge(other, func(_ string, ours, _ interface{}) interface{} {
+		return ours
+	})
+
+	return res
+}
+
+// Intersect returns a new trie with the keys stored in both t and
+// other, with the metas they have in t. The new trie is configured
+// like t, as described for Filter.
+//
+// Both tries are walked in lockstep, so branches of t missing from
+// other are skipped as a whole. Keys are compared as stored.
+func (t *Trie) Intersect(other *Trie) *Trie {
+	res := t.derive()
+	res.intersect(t.Root(), other.Root(), nil)
+	return res
+}
+
+// Difference returns a new trie with the keys stored in t but not in
+// other, with their metas. The new trie is configured like t, as
+// described for Filter.
+//
+// Both tries are walked in lockstep, so branches of t missing from
+// other are copied as a whole. Keys are compared as stored.
+func (t *Trie) Difference(other *Trie) *Trie {
+	res := t.derive()
+	res.difference(t.Root(), other.Root(), nil)
+	return res
+}
+
+func (t *Trie) intersect(a, b *Node, pre []rune) {
+	children := a.children
+	for _, r := range sortedrunes(children) {
+		n := children[r]
+		o, ok := b.children[r]
+		if !ok {
+			continue
+		}
+
+		if n.term {
+			if n.alive() && o.alive() {
+				t.copykey(string(pre), n)
+			}
+			continue
+		}
+
+		t.intersect(n, o, append(pre, r))
+	}
+}
+
+func (t *Trie) difference(a, b *Node, pre []rune) {
+	children := a.children
+	for _, r := range sortedrunes(children) {
+		n := children[r]
+		o, ok := b.children[r]
+
+		switch {
+		case ok && n.term:
+			if n.alive() && !o.alive() {
+				t.copykey(string(pre), n)
+			}
+		case ok:
+			t.difference(n, o, append(pre, r))
+		case n.term:
+			if n.alive() {
+				t.copykey(string(pre), n)
+			}
+		default:
+			collectfunc(n, append(pre, r), func(key []rune, term *Node) bool {
+				t.copykey(string(key), term)
+				return true
+			})
+		}
+	}
+}
+
+// Equal reports whether t and other store the same keys, regardless
+// of their metas. A nil trie is equal to an empty one.
+func (t *Trie) Equal(other *Trie) bool {
+	return t.EqualFunc(other, nil)
+}
+
+// EqualFunc is like Equal but also requires the metas of every key
+// to be equal according to metaEq.
+func (t *Trie) EqualFunc(other *Trie, metaEq func(a, b interface{}) bool) bool {
+	if t == nil || other == nil {
+		return (t == nil || t.size == 0) && (other == nil || other.size == 0)
+	}
+
+	if t.size != other.size {
+		return false
+	}
+
+	if t.tombs > 0 || other.tombs > 0 {
+		// Keys marked deleted may leave branches in one trie
+		// which hold no keys, so the nodes can't be compared.
+		eq := metaEq
+		if eq == nil {
+			eq = func(a, b interface{}) bool { return true }
+		}
+
+		added, removed, changed := t.DiffFunc(other, eq)
+		return len(added) == 0 && len(removed) == 0 && len(changed) == 0
+	}
+
+	return equalnode(t.Root(), other.Root(), metaEq)
+}
+
+func equalnode(a, b *Node, metaEq func(a, b interface{}) bool) bool {
+	if len(a.children) != len(b.children) {
+		return false
+	}
+
+	for r, n := range a.children {
+		o, ok := b.children[r]
+		if !ok || n.term != o.term {
+			return false
+		}
+
+		if n.term {
+			if metaEq != nil && !metaEq(n.meta, o.meta) {
+				return false
+			}
+			continue
+		}
+
+		if !equalnode(n, o, metaEq) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Diff describes how to get from t to other. added are the keys only
+// stored in other, removed the keys only stored in t, and changed the
+// keys stored in both whose metas differ according to reflect.DeepEqual.
+// Every slice is in lexicographic order. A nil trie is treated like
+// an empty one.
+func (t *Trie) Diff(other *Trie) (added, removed, changed []string) {
+	return t.DiffFunc(other, reflect.DeepEqual)
+}
+
+// DiffFunc is like Diff but compares the metas using metaEq.
+func (t *Trie) DiffFunc(other *Trie, metaEq func(a, b interface{}) bool) (added, removed, changed []string) {
+	var d diff
+	d.node(rootof(t), rootof(other), nil, metaEq)
+	return d.added, d.removed, d.changed
+}
+
+// rootof returns the root of t, or an empty root if t is nil.
+func rootof(t *Trie) *Node {
+	if t == nil {
+		return newNode(nil, 0, 0, false)
+	}
+
+	return t.Root()
+}
+
+type diff struct {
+	added, removed, changed []string
+}
+
+// node compares the nodes below a and b, which are the nodes
+// of the path pre in both tries.
+func (d *diff) node(a, b *Node, pre []rune, metaEq func(a, b interface{}) bool) {
+	for _, r := range unionrunes(a.children, b.children) {
+		n, inA := a.children[r]
+		o, inB := b.children[r]
+
+		switch {
+		case !inB:
+			d.removed = appendkeys(d.removed, n, pre, r)
+		case !inA:
+			d.added = appendkeys(d.added, o, pre, r)
+		case n.term:
+			switch {
+			case n.alive() && o.alive():
+				if !metaEq(n.meta, o.meta) {
+					d.changed = append(d.changed, string(pre))
+				}
+			case n.alive():
+				d.removed = append(d.removed, string(pre))
+			case o.alive():
+				d.added = append(d.added, string(pre))
+			}
+		default:
+			d.node(n, o, append(pre, r), metaEq)
+		}
+	}
+}
+
+// appendkeys appends to keys the keys of the child n
+// of the node of the path pre, which is reached by r.
+func appendkeys(keys []string, n *Node, pre []rune, r rune) []string {
+	if n.term {
+		if n.alive() {
+			keys = append(keys, string(pre))
+		}
+		return keys
+	}
+
+	collect(n, append(pre, r), &keys, 0)
+	return keys
+}
+
+// unionrunes returns the runes of the children of a
+// or b in ascending order.
+func unionrunes(a, b map[rune]*Node) []rune {
+	runes := make([]rune, 0, len(a)+len(b))
+	for r := range a {
+		runes = append(runes, r)
+	}
+	for r := range b {
+		if _, ok := a[r]; !ok {
+			runes = append(runes, r)
+		}
+	}
+
+	sort.Slice(runes, func(i, j int) bool { return runes[i] < runes[j] })
+	return runes
+}
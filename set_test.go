@@ -0,0 +1,23 @@
+package trie
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffNil(t *testing.T) {
+	trie := NewTrie()
+	trie.Add("foo", nil)
+	trie.Add("bar", nil)
+
+	added, removed, changed := trie.Diff(nil)
+	if added != nil || !reflect.DeepEqual(removed, []string{"bar", "foo"}) || changed != nil {
+		t.Errorf("expected every key to be removed, got %v, %v and %v", added, removed, changed)
+	}
+
+	var empty *Trie
+	added, removed, changed = empty.Diff(trie)
+	if !reflect.DeepEqual(added, []string{"bar", "foo"}) || removed != nil || changed != nil {
+		t.Errorf("expected every key to be added, got %v, %v and %v", added, removed, changed)
+	}
+}
@@ -0,0 +1,43 @@
+package trie
+
+// Store adapts a Trie to a plain key value store interface, with
+// the metas of the keys as their values.
+type Store struct {
+	t *Trie
+}
+
+// NewStore returns a Store backed by t, or by a new trie if t is nil.
+func NewStore(t *Trie) *Store {
+	if t == nil {
+		t = NewTrie()
+	}
+
+	return &Store{t: t}
+}
+
+// Trie returns the trie backing the store.
+func (s *Store) Trie() *Trie {
+	return s.t
+}
+
+// Get returns the value of key. The bool is false if key is not
+// in the store.
+func (s *Store) Get(key string) (interface{}, bool) {
+	return s.t.Get(key)
+}
+
+// Set stores value as the value of key, replacing the old one.
+func (s *Store) Set(key string, value interface{}) {
+	s.t.Add(key, value)
+}
+
+// Delete removes key and reports whether it was in the store.
+func (s *Store) Delete(key string) bool {
+	return s.t.Remove(key)
+}
+
+// Iterate calls fn with every key and its value, in lexicographic
+// order, until fn returns false.
+func (s *Store) Iterate(fn func(key string, value interface{}) bool) {
+	s.t.Walk(fn)
+}
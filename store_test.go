@@ -0,0 +1,44 @@
+package trie
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	s := NewStore(nil)
+	s.Set("foo", 1)
+	s.Set("bar", 2)
+	s.Set("foo", 3)
+
+	if v, ok := s.Get("foo"); !ok || v != 3 {
+		t.Errorf("expected Set to replace the value of foo, got %v, %t", v, ok)
+	}
+	if _, ok := s.Get("baz"); ok {
+		t.Error("expected baz to be missing")
+	}
+	if s.Trie().Len() != 2 {
+		t.Errorf("expected 2 keys, got %d", s.Trie().Len())
+	}
+
+	if !s.Delete("bar") || s.Delete("bar") {
+		t.Error("expected Delete to report whether bar was in the store")
+	}
+}
+
+func TestStoreIterate(t *testing.T) {
+	s := NewStore(nil)
+	for i, key := range []string{"c", "a", "b"} {
+		s.Set(key, i)
+	}
+
+	var keys []string
+	s.Iterate(func(key string, _ interface{}) bool {
+		keys = append(keys, key)
+		return key != "b"
+	})
+
+	if !reflect.DeepEqual(keys, []string{"a", "b"}) {
+		t.Errorf("expected Iterate to stop after b, got %v", keys)
+	}
+}
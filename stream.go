@@ -0,0 +1,118 @@
+package trie
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// streamBuffer is the capacity of the channels
+// returned by the streaming searches.
+const streamBuffer = 64
+
+// PrefixSearchChan is like PrefixSearch but sends the keys on the
+// returned channel while the trie is still being traversed. The
+// channel is closed once all keys have been sent or ctx is done,
+// so cancelling ctx is how a caller abandons the search early.
+//
+// The traversal reads the trie while the caller consumes keys, so
+// the trie must not be modified until the channel has been closed.
+func (t *Trie) PrefixSearchChan(ctx context.Context, pre string) <-chan string {
+	pre = t.normalize(pre)
+	ch := make(chan string, streamBuffer)
+
+	go func() {
+		defer close(ch)
+
+		node := t.nodeAtPath(pre)
+		if node == nil {
+			return
+		}
+
+		collectfunc(node, []rune(pre), func(key []rune, _ *Node) bool {
+			return send(ctx, ch, string(key))
+		})
+	}()
+
+	return ch
+}
+
+// FuzzySearchChan is like FuzzySearch but sends the keys on the
+// returned channel, in the same way as PrefixSearchChan.
+func (t *Trie) FuzzySearchChan(ctx context.Context, partial string) <-chan string {
+	partial = t.normalize(partial)
+	ch := make(chan string, streamBuffer)
+
+	go func() {
+		defer close(ch)
+
+		c := &canceller{ctx: ctx}
+		fuzzycollect(t.Root(), nil, []rune(partial), fuzzymatch{}, c, func(key []rune, _ *Node, _ fuzzymatch) bool {
+			return send(ctx, ch, string(key))
+		})
+	}()
+
+	return ch
+}
+
+func send(ctx context.Context, ch chan<- string, key string) bool {
+	select {
+	case ch <- key:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// PrefixSearchMulti runs PrefixSearch for every prefix concurrently,
+// on at most GOMAXPROCS goroutines, and returns the keys found for
+// every prefix. Duplicate prefixes are only searched once.
+//
+// The searches only read the trie, which must not be modified
+// until PrefixSearchMulti returns.
+func (t *Trie) PrefixSearchMulti(prefixes []string) map[string][]string {
+	var (
+		res    = make(map[string][]string, len(prefixes))
+		unique []string
+	)
+
+	for _, pre := range prefixes {
+		if _, ok := res[pre]; !ok {
+			res[pre] = nil
+			unique = append(unique, pre)
+		}
+	}
+
+	work := make(chan string)
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(unique) {
+		workers = len(unique)
+	}
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for pre := range work {
+				keys := t.PrefixSearch(pre)
+
+				mu.Lock()
+				res[pre] = keys
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, pre := range unique {
+		work <- pre
+	}
+	close(work)
+
+	wg.Wait()
+	return res
+}
@@ -0,0 +1,95 @@
+package trie
+
+import (
+	"sort"
+	"strings"
+)
+
+// SuffixSearch returns all keys ending with suffix, sorted like
+// the results of PrefixSearch. Without WithSuffixIndex every key in
+// the trie has to be visited.
+func (t *Trie) SuffixSearch(suffix string) []string {
+	suffix = t.normalize(suffix)
+	var keys []string
+	if t.suffix == nil {
+		collectfunc(t.Root(), nil, func(key []rune, _ *Node) bool {
+			if k := string(key); strings.HasSuffix(k, suffix) {
+				keys = append(keys, k)
+			}
+			return true
+		})
+		return keys
+	}
+
+	for _, k := range t.suffix.PrefixSearch(reverse(suffix)) {
+		keys = append(keys, reverse(k))
+	}
+
+	// The index yields the keys in the order of their reversals.
+	sort.Strings(keys)
+	return keys
+}
+
+func reverse(s string) string {
+	rs := []rune(s)
+	for i, j := 0, len(rs)-1; i < j; i, j = i+1, j-1 {
+		rs[i], rs[j] = rs[j], rs[i]
+	}
+
+	return string(rs)
+}
+
+// ContainsSearch returns all keys containing sub anywhere. The
+// trie is walked once while carrying the state of a KMP matcher
+// for sub, and once sub has been matched all keys below are taken.
+func (t *Trie) ContainsSearch(sub string) []string {
+	sub = t.normalize(sub)
+	var keys []string
+
+	pattern := []rune(sub)
+	kmpcollect(t.Root(), nil, pattern, kmpfailure(pattern), 0, &keys)
+	return keys
+}
+
+// kmpfailure returns the KMP failure function of pattern, the
+// length of the longest proper prefix of pattern[:i+1] which is
+// also a suffix of it.
+func kmpfailure(pattern []rune) []int {
+	fail := make([]int, len(pattern))
+	for i, k := 1, 0; i < len(pattern); i++ {
+		for k > 0 && pattern[i] != pattern[k] {
+			k = fail[k-1]
+		}
+		if pattern[i] == pattern[k] {
+			k++
+		}
+		fail[i] = k
+	}
+
+	return fail
+}
+
+func kmpcollect(node *Node, pre, pattern []rune, fail []int, state int, keys *[]string) {
+	if state == len(pattern) {
+		collect(node, pre, keys, 0)
+		return
+	}
+
+	children := node.children
+	for _, r := range sortedrunes(children) {
+		n := children[r]
+		if n.term {
+			continue
+		}
+
+		k := state
+		for k > 0 && r != pattern[k] {
+			k = fail[k-1]
+		}
+		if r == pattern[k] {
+			k++
+		}
+
+		kmpcollect(n, append(pre, r), pattern, fail, k, keys)
+	}
+}
@@ -0,0 +1,22 @@
+package trie
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSuffixSearch(t *testing.T) {
+	keys := []string{"bar", "zba", "ab", "cab", "b", "bab"}
+	want := []string{"ab", "b", "bab", "cab"}
+
+	for _, opts := range [][]Option{nil, {WithSuffixIndex()}} {
+		trie := NewTrie(opts...)
+		for _, key := range keys {
+			trie.Add(key, nil)
+		}
+
+		if got := trie.SuffixSearch("b"); !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v with %d options, got %v", want, len(opts), got)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package trie
+
+import "math"
+
+// MarkDeleted removes key from the trie like Remove, regardless of
+// its count, except that its terminator node is only flagged as
+// deleted and stays in the trie, with its meta, until Compact is
+// called. A key marked deleted is absent from every lookup and
+// search, and isn't counted by Len. Adding it again stores it on the
+// same node. It reports whether key was stored in the trie.
+func (t *Trie) MarkDeleted(key string) bool {
+	key = t.normalize(key)
+	node := t.terminal(key)
+	if node == nil {
+		return false
+	}
+
+	if t.counted {
+		t.total -= node.count()
+	}
+
+	node.entry().dead = true
+	t.tombs++
+
+	old := node.best
+	node.best = math.Inf(-1)
+	node.propagateBest(old)
+
+	t.forget(node, key)
+	return true
+}
+
+// Deleted returns the keys marked deleted since
+// the last Compact, in lexicographic order.
+func (t *Trie) Deleted() []string {
+	keys := make([]string, 0, t.tombs)
+	tombcollect(t.Root(), nil, func(key []rune, _ *Node) {
+		keys = append(keys, string(key))
+	})
+
+	return keys
+}
+
+// Compact removes the nodes of the keys marked deleted from the trie,
+// pruning the branches left without keys, and returns how many keys
+// there were.
+func (t *Trie) Compact() int {
+	if t.tombs == 0 {
+		return 0
+	}
+
+	var dead []*Node
+	tombcollect(t.Root(), nil, func(_ []rune, n *Node) {
+		dead = append(dead, n)
+	})
+
+	for _, n := range dead {
+		unlink(n)
+	}
+
+	t.tombs = 0
+	t.version++
+	return len(dead)
+}
+
+// tombcollect calls fn with every key marked deleted below node
+// and its terminator node in lexicographic order.
+func tombcollect(node *Node, pre []rune, fn func(key []rune, term *Node)) {
+	children := node.children
+	for _, r := range sortedrunes(children) {
+		n := children[r]
+		if n.term {
+			if !n.alive() {
+				fn(pre, n)
+			}
+			continue
+		}
+
+		tombcollect(n, append(pre, r), fn)
+	}
+}
@@ -0,0 +1,113 @@
+package trie
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMarkDeleted(t *testing.T) {
+	trie := NewTrie()
+	trie.Add("foo", 1)
+	trie.Add("foobar", 2)
+	trie.Add("bar", 3)
+
+	if !trie.MarkDeleted("foo") {
+		t.Fatal("expected foo to be marked deleted")
+	}
+	if trie.MarkDeleted("foo") {
+		t.Error("expected foo to be marked deleted only once")
+	}
+
+	if trie.Len() != 2 {
+		t.Errorf("expected Len to leave out the deleted key, got %d", trie.Len())
+	}
+	if _, err := trie.Find("foo"); err == nil {
+		t.Error("expected Find to miss a deleted key")
+	}
+	if trie.Contains("foo") {
+		t.Error("expected Contains to miss a deleted key")
+	}
+	if keys := trie.Keys(); !reflect.DeepEqual(keys, []string{"bar", "foobar"}) {
+		t.Errorf("expected [bar foobar], got %v", keys)
+	}
+	if keys := trie.PrefixSearch("foo"); !reflect.DeepEqual(keys, []string{"foobar"}) {
+		t.Errorf("expected [foobar], got %v", keys)
+	}
+	if keys := trie.Deleted(); !reflect.DeepEqual(keys, []string{"foo"}) {
+		t.Errorf("expected [foo] to be deleted, got %v", keys)
+	}
+
+	// The terminator stays in the trie with its meta.
+	_, _, node := trie.Diagnose("foo")
+	term := node.Children()[nul]
+	if term == nil || term.Meta() != 1 {
+		t.Fatalf("expected the terminator of foo to be kept, got %v", term)
+	}
+
+	if n := trie.Compact(); n != 1 {
+		t.Errorf("expected Compact to remove 1 key, got %d", n)
+	}
+	if _, ok := node.Children()[nul]; ok {
+		t.Error("expected Compact to remove the terminator of foo")
+	}
+	if keys := trie.Deleted(); len(keys) != 0 {
+		t.Errorf("expected no deleted keys after Compact, got %v", keys)
+	}
+	if keys := trie.Keys(); !reflect.DeepEqual(keys, []string{"bar", "foobar"}) {
+		t.Errorf("expected [bar foobar] after Compact, got %v", keys)
+	}
+}
+
+func TestMarkDeletedAddAgain(t *testing.T) {
+	trie := NewTrie()
+	trie.Add("foo", 1)
+	before, _ := trie.Find("foo")
+
+	trie.MarkDeleted("foo")
+	after := trie.Add("foo", 2)
+	if after != before {
+		t.Error("expected the key to be added again on its old node")
+	}
+	if m, ok := trie.Get("foo"); !ok || m != 2 {
+		t.Errorf("expected foo with 2, got %v, %t", m, ok)
+	}
+	if trie.Len() != 1 {
+		t.Errorf("expected 1 key, got %d", trie.Len())
+	}
+	if keys := trie.Deleted(); len(keys) != 0 {
+		t.Errorf("expected no deleted keys, got %v", keys)
+	}
+	if n := trie.Compact(); n != 0 {
+		t.Errorf("expected nothing to compact, got %d", n)
+	}
+}
+
+func TestMarkDeletedBranch(t *testing.T) {
+	trie := NewTrie()
+	trie.Add("ab", nil)
+	trie.Add("abc", nil)
+	trie.Add("b", nil)
+	trie.MarkDeleted("abc")
+	trie.MarkDeleted("b")
+
+	if lcp := trie.LongestCommonPrefix(); lcp != "ab" {
+		t.Errorf("expected ab as longest common prefix, got %q", lcp)
+	}
+	if m, _ := trie.Max(); m != "ab" {
+		t.Errorf("expected ab as max, got %q", m)
+	}
+
+	other := NewTrie()
+	other.Add("ab", nil)
+	if !trie.Equal(other) {
+		t.Error("expected the tries to be equal regardless of deleted keys")
+	}
+
+	trie.Compact()
+	if keys := trie.Keys(); !reflect.DeepEqual(keys, []string{"ab"}) {
+		t.Errorf("expected [ab], got %v", keys)
+	}
+	if trie.Root().ChildCount() != 1 {
+		t.Errorf("expected the branch of b to be pruned, got %v", trie.Root())
+	}
+}
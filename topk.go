@@ -0,0 +1,76 @@
+package trie
+
+import "container/heap"
+
+// TopK returns the k keys starting with prefix which have the
+// highest weights, as computed by the function given to
+// WithWeightFunc, in descending order of weight. Keys of equal
+// weight are ordered lexicographically. A value of k <= 0 means
+// no limit. Without a weight function keys weigh what Increment
+// made them weigh, which is 0 for keys never incremented.
+//
+// Every node keeps the highest weight of any key below it, so the
+// search only expands the most promising nodes.
+func (t *Trie) TopK(prefix string, k int) []string {
+	prefix = t.normalize(prefix)
+	var keys []string
+
+	node := t.nodeAtPath(prefix)
+	if node == nil {
+		return keys
+	}
+
+	h := &candidates{{node: node, pre: []rune(prefix)}}
+	for h.Len() > 0 {
+		c := heap.Pop(h).(candidate)
+		if c.node.term {
+			if !c.node.alive() {
+				continue
+			}
+
+			keys = append(keys, string(c.pre))
+			if k > 0 && len(keys) >= k {
+				break
+			}
+			continue
+		}
+
+		for r, n := range c.node.children {
+			pre := c.pre
+			if !n.term {
+				pre = append(pre[:len(pre):len(pre)], r)
+			}
+			heap.Push(h, candidate{node: n, pre: pre})
+		}
+	}
+
+	return keys
+}
+
+type candidate struct {
+	node *Node
+	pre  []rune
+}
+
+// candidates is a max heap of nodes ordered by their best weight,
+// and by their prefix for nodes of equal weight.
+type candidates []candidate
+
+func (c candidates) Len() int      { return len(c) }
+func (c candidates) Swap(i, j int) { c[i], c[j] = c[j], c[i] }
+func (c candidates) Less(i, j int) bool {
+	if c[i].node.best != c[j].node.best {
+		return c[i].node.best > c[j].node.best
+	}
+
+	return string(c[i].pre) < string(c[j].pre)
+}
+
+func (c *candidates) Push(x interface{}) { *c = append(*c, x.(candidate)) }
+
+func (c *candidates) Pop() interface{} {
+	old := *c
+	x := old[len(old)-1]
+	*c = old[:len(old)-1]
+	return x
+}
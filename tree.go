@@ -1,7 +1,14 @@
 package trie
 
 import (
+	"container/list"
+	"errors"
 	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
 )
 
 // Node provides implementation of the node at trie
@@ -10,14 +17,45 @@ type Node struct {
 	term     bool
 	meta     interface{}
 	mask     uint64
+	best     float64
+	ext      *entry
 	parent   *Node
 	children map[rune]*Node
 }
 
+// entry holds the per key state of the opt-in features of the
+// trie. It is only allocated on terminator nodes and only when
+// one of those features is enabled.
+type entry struct {
+	touched int64
+	more    []interface{}
+	count   int
+	weight  float64
+	expires int64
+	elem    *list.Element
+	dead    bool
+}
+
 // Trie is a main structure
 type Trie struct {
-	root *Node
-	size int
+	root     *Node
+	size     int
+	suffix   *Trie
+	weight   func(meta interface{}) float64
+	recency  bool
+	fold     bool
+	norm     func(string) string
+	version  uint64
+	counted  bool
+	total    int
+	onadd    func(key string, meta interface{})
+	onremove func(key string, meta interface{})
+	now      func() time.Time
+	max      int
+	lru      *list.List
+	onevict  func(key string, meta interface{})
+	tombs    int
+	metas    map[interface{}]map[string]struct{}
 }
 
 // ByKeys provides comparation of the keys on trie
@@ -29,11 +67,18 @@ func (a ByKeys) Less(i, j int) bool { return len(a[i]) < len(a[j]) }
 
 const nul = 0x0
 
+// ErrNulKey is returned by AddChecked for a key containing the nul
+// rune, which the trie uses internally to mark the end of a key.
+var ErrNulKey = errors.New("key contains the nul rune")
+
 func newNode(parent *Node, val rune, m uint64, term bool) *Node {
+	// Nothing below the node weighs anything yet,
+	// not even the key of a new terminator.
 	return &Node{
 		val:      val,
 		mask:     m,
 		term:     term,
+		best:     math.Inf(-1),
 		parent:   parent,
 		children: make(map[rune]*Node),
 	}
@@ -50,19 +95,75 @@ func (n *Node) NewChild(r rune, bitmask uint64, val rune, term bool) *Node {
 func (n *Node) RemoveChild(r rune) {
 	delete(n.children, r)
 
-	n.recalculateMask()
-	for parent := n.Parent(); parent != nil; parent = parent.Parent() {
-		parent.recalculateMask()
+	// Once a node keeps its mask and best weight,
+	// so do all the nodes above it.
+	for node := n; node != nil; node = node.Parent() {
+		mask, best := node.mask, node.best
+		node.recalculateMask()
+		node.recalculateBest()
+		if node.mask == mask && node.best == best {
+			break
+		}
 	}
 }
 
 func (n *Node) recalculateMask() {
 	n.mask = maskrune(n.Val())
-	for k, c := range n.Children() {
+	for k, c := range n.children {
 		n.mask |= (maskrune(k) | c.Mask())
 	}
 }
 
+// recalculateBest sets the best weight of a non terminal
+// node to the highest weight of any key below it.
+func (n *Node) recalculateBest() {
+	if n.term {
+		return
+	}
+
+	n.best = math.Inf(-1)
+	for _, c := range n.children {
+		if c.best > n.best {
+			n.best = c.best
+		}
+	}
+}
+
+// alive reports whether n is the terminator
+// of a key which isn't marked deleted.
+func (n *Node) alive() bool {
+	return n.term && (n.ext == nil || !n.ext.dead)
+}
+
+// propagateBest updates the best weights of the ancestors of the
+// terminator node n, whose weight has just changed from old. Going
+// up it stops at the first ancestor which keeps its best weight.
+func (n *Node) propagateBest(old float64) {
+	if n.best >= old {
+		for p := n.Parent(); p != nil && p.best < n.best; p = p.Parent() {
+			p.best = n.best
+		}
+		return
+	}
+
+	for p := n.Parent(); p != nil; p = p.Parent() {
+		best := p.best
+		p.recalculateBest()
+		if p.best == best {
+			return
+		}
+	}
+}
+
+// entry returns the entry of n, allocating it if needed.
+func (n *Node) entry() *entry {
+	if n.ext == nil {
+		n.ext = &entry{}
+	}
+
+	return n.ext
+}
+
 // Parent returns the parent of this node.
 func (n Node) Parent() *Node {
 	return n.parent
@@ -73,9 +174,21 @@ func (n Node) Meta() interface{} {
 	return n.meta
 }
 
-//Children of this node.
+// Children returns a copy of the children of this node, keyed by
+// their rune. Changing the returned map doesn't change the trie.
 func (n Node) Children() map[rune]*Node {
-	return n.children
+	children := make(map[rune]*Node, len(n.children))
+	for r, c := range n.children {
+		children[r] = c
+	}
+
+	return children
+}
+
+// ChildRunes returns the runes of the children of this node in
+// ascending order, so the terminator, if any, comes first.
+func (n Node) ChildRunes() []rune {
+	return sortedrunes(n.children)
 }
 
 // Val is a value of node
@@ -89,13 +202,216 @@ func (n Node) Mask() uint64 {
 	return n.mask
 }
 
+// Siblings returns the other children of the parent of this node,
+// ordered by their rune, leaving out the terminator. It returns an
+// empty slice for the root.
+func (n Node) Siblings() []*Node {
+	siblings := []*Node{}
+	if n.parent == nil {
+		return siblings
+	}
+
+	for _, r := range sortedrunes(n.parent.children) {
+		c := n.parent.children[r]
+		if r != n.val && !c.term {
+			siblings = append(siblings, c)
+		}
+	}
+
+	return siblings
+}
+
+// Path returns the nodes from the root down to this node,
+// both included.
+func (n *Node) Path() []*Node {
+	path := make([]*Node, n.Depth()+1)
+	for i, node := len(path)-1, n; node != nil; i, node = i-1, node.parent {
+		path[i] = node
+	}
+
+	return path
+}
+
+// Walk calls fn with this node and every node below it, depth first
+// and with the children of a node ordered by their rune, until fn
+// returns false. Terminators are visited too.
+func (n *Node) Walk(fn func(n *Node) bool) {
+	n.walk(fn)
+}
+
+func (n *Node) walk(fn func(n *Node) bool) bool {
+	if !fn(n) {
+		return false
+	}
+
+	for _, r := range sortedrunes(n.children) {
+		if !n.children[r].walk(fn) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// HasRune reports whether r is the rune of this node or of any node
+// below it, as recorded in its mask. The mask only has room for the
+// runes from 'a' up to 63 runes after it, so HasRune returns true for
+// any other rune, instead of telling wrongly that r isn't there.
+func (n Node) HasRune(r rune) bool {
+	m := maskrune(r)
+	return m == 0 || n.mask&m != 0
+}
+
+// String describes this node for debugging, listing the runes of its
+// children and the runes of its mask, with $ standing for the
+// terminator. It doesn't descend into the children.
+func (n Node) String() string {
+	val := fmt.Sprintf("%q", n.val)
+	switch {
+	case n.term:
+		val = "'$'"
+	case n.parent == nil:
+		val = "root"
+	}
+
+	children := make([]string, 0, len(n.children))
+	for _, r := range sortedrunes(n.children) {
+		if r == nul {
+			children = append(children, "$")
+			continue
+		}
+		children = append(children, string(r))
+	}
+
+	var mask []rune
+	for i := 0; i < 64; i++ {
+		if n.mask&(1<<uint(i)) != 0 {
+			mask = append(mask, rune('a'+i))
+		}
+	}
+
+	return fmt.Sprintf("Node{val: %s, term: %t, children: [%s], mask: %q}",
+		val, n.term, strings.Join(children, " "), string(mask))
+}
+
+// Terminal reports whether this node is the terminator of a key,
+// which is the nul child of the node of the last rune of the key.
+// The terminator of a key marked deleted stays one until Compact.
+func (n Node) Terminal() bool {
+	return n.term
+}
+
+// IsLeaf reports whether no key continues below this node, that
+// is, whether it has no children other than a terminator. For the
+// node of the last rune of a key it reports whether no longer key
+// starts with that key.
+func (n Node) IsLeaf() bool {
+	for r := range n.children {
+		if r != nul || !n.children[r].term {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Depth returns the number of edges between this node and the root.
+// The root has a depth of 0, the node of the last rune of a key the
+// length of the key in runes, and its terminator one more.
+func (n Node) Depth() int {
+	var d int
+	for p := n.parent; p != nil; p = p.parent {
+		d++
+	}
+
+	return d
+}
+
+// ChildCount returns the number of children of this node,
+// including the terminator, if any.
+func (n Node) ChildCount() int {
+	return len(n.children)
+}
+
+// HasChildren reports whether this node has any children,
+// including the terminator.
+func (n Node) HasChildren() bool {
+	return len(n.children) > 0
+}
+
+// Child returns the child of this node for the rune r. The bool is
+// false if there is none. The terminator is the child for nul.
+func (n Node) Child(r rune) (*Node, bool) {
+	c, ok := n.children[r]
+	return c, ok
+}
+
+// HasChild reports whether this node has a child for the rune r.
+func (n Node) HasChild(r rune) bool {
+	_, ok := n.children[r]
+	return ok
+}
+
+// Key returns the key of the path from the root to this node. The
+// terminator node of a key and the node of its last rune both
+// return the key.
+func (n Node) Key() string {
+	var runes []rune
+	for node := &n; node.Parent() != nil; node = node.Parent() {
+		if !node.term {
+			runes = append(runes, node.Val())
+		}
+	}
+
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+
+	return string(runes)
+}
+
 // NewTrie a new Trie with an initialized root Node.
-func NewTrie() *Trie {
+func NewTrie(opts ...Option) *Trie {
 	node := newNode(nil, 0, 0, false)
-	return &Trie{
+	t := &Trie{
 		root: node,
 		size: 0,
 	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	return t
+}
+
+// NewTrieFromMap creates a Trie holding every key of m with its
+// meta. The keys are added in lexicographic order, so that keys
+// sharing a prefix are added one after the other.
+func NewTrieFromMap(m map[string]interface{}, opts ...Option) *Trie {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	t := NewTrie(opts...)
+	for _, key := range keys {
+		t.Add(key, m[key])
+	}
+
+	return t
+}
+
+// NewTrieFromKeys creates a Trie holding every distinct key
+// of keys, with a nil meta.
+func NewTrieFromKeys(keys []string, opts ...Option) *Trie {
+	sorted := append([]string(nil), keys...)
+	sort.Strings(sorted)
+
+	t := NewTrie(opts...)
+	t.AddKeys(sorted)
+	return t
 }
 
 // Root returns the root node for the Trie.
@@ -103,55 +419,732 @@ func (t *Trie) Root() *Node {
 	return t.root
 }
 
+// Clear removes all keys from the trie, leaving it ready for use.
+func (t *Trie) Clear() {
+	var gone []KV
+	if t.onremove != nil {
+		gone = kvs(t.Root(), nil)
+	}
+
+	t.root = newNode(nil, 0, 0, false)
+	t.size = 0
+	t.total = 0
+	t.tombs = 0
+	t.version++
+
+	if t.metas != nil {
+		t.metas = make(map[interface{}]map[string]struct{})
+	}
+
+	if t.lru != nil {
+		t.lru.Init()
+	}
+
+	if t.suffix != nil {
+		t.suffix.Clear()
+	}
+
+	t.removed(gone)
+}
+
+// Len returns the number of keys stored in the trie.
+// Keys marked deleted aren't counted.
+func (t *Trie) Len() int {
+	return t.size
+}
+
+// Empty reports whether the trie has no keys.
+func (t *Trie) Empty() bool {
+	return t.size == 0
+}
+
 // Add the key to the Trie, including meta data.
+// If the key is already stored, its meta is replaced.
 func (t *Trie) Add(key string, meta interface{}) *Node {
-	t.size++
-	runes := []rune(key)
-	node := t.addrune(t.Root(), runes, 0)
-	node.meta = meta
+	node, _ := t.AddNew(key, meta)
 	return node
 }
 
+// AddNew is like Add but also reports whether the key is new,
+// that is, whether it wasn't stored in the trie before.
+func (t *Trie) AddNew(key string, meta interface{}) (*Node, bool) {
+	node, added := t.put(key, func(interface{}, bool) interface{} {
+		return meta
+	})
+
+	if node.ext != nil {
+		node.ext.more = nil
+		node.ext.expires = 0
+	}
+
+	if t.counted && !added {
+		node.ext.count++
+		t.total++
+	}
+
+	return node, added
+}
+
+// AddChecked is like Add but refuses keys containing the nul rune,
+// which can't be told apart from the end of a key. Add stores such
+// keys anyway, and they break lookups of other keys.
+func (t *Trie) AddChecked(key string, meta interface{}) (*Node, error) {
+	if strings.ContainsRune(t.normalize(key), nul) {
+		return nil, ErrNulKey
+	}
+
+	return t.Add(key, meta), nil
+}
+
+// AddKey adds the key with a nil meta.
+func (t *Trie) AddKey(key string) *Node {
+	return t.Add(key, nil)
+}
+
+// AddAll adds every key of entries with its meta and returns
+// how many of the keys were new.
+func (t *Trie) AddAll(entries map[string]interface{}) int {
+	var added int
+	for key, meta := range entries {
+		if _, ok := t.AddNew(key, meta); ok {
+			added++
+		}
+	}
+
+	return added
+}
+
+// AddKeys adds every key with a nil meta and returns
+// how many of the keys were new.
+func (t *Trie) AddKeys(keys []string) int {
+	var added int
+	for _, key := range keys {
+		if _, ok := t.AddNew(key, nil); ok {
+			added++
+		}
+	}
+
+	return added
+}
+
+// Upsert adds the key like Add if it isn't stored yet. Otherwise the
+// meta of the key is replaced by the result of merge, called with its
+// current meta and the given one. If merge panics the key is left
+// untouched.
+func (t *Trie) Upsert(key string, meta interface{}, merge func(old, new interface{}) interface{}) *Node {
+	node, _ := t.put(key, func(old interface{}, exists bool) interface{} {
+		if !exists {
+			return meta
+		}
+		return merge(old, meta)
+	})
+	return node
+}
+
+// GetOrAdd returns the meta of key if it is stored in the trie.
+// Otherwise it adds key with the given meta and returns that.
+// The bool reports whether key was already stored.
+func (t *Trie) GetOrAdd(key string, meta interface{}) (interface{}, bool) {
+	node, added := t.put(key, func(old interface{}, exists bool) interface{} {
+		if exists {
+			return old
+		}
+		return meta
+	})
+	return node.Meta(), !added
+}
+
+// put stores key with the meta returned by value, which is called
+// with the current meta of key and whether key is already stored.
+// The bool reports whether the key is new.
+func (t *Trie) put(key string, value func(old interface{}, exists bool) interface{}) (*Node, bool) {
+	key = t.normalize(key)
+	runes := []rune(key)
+	// addrune only updates the masks of the nodes below the root.
+	t.Root().mask |= maskruneslice(runes)
+	node, added := t.addrune(t.Root(), runes, 0)
+	if !added && (!node.alive() || t.live(node) == nil) {
+		if node.alive() {
+			// An expired key goes like Sweep would remove it.
+			if t.counted {
+				t.total -= node.count()
+			}
+			t.forget(node, key)
+		} else {
+			t.tombs--
+		}
+
+		// A key marked deleted or expired is stored again on its
+		// old terminator, with none of its former state.
+		*node.ext = entry{}
+		added = true
+	}
+	old := node.meta
+	node.meta = value(old, !added)
+
+	if !added {
+		t.unindex(key, old)
+	}
+	t.index(key, node.meta)
+
+	if added {
+		t.size++
+		t.version++
+
+		if t.counted {
+			node.entry().count = 1
+			t.total++
+		}
+	}
+
+	// A new key weighs 0 without a weight function, which may be
+	// more than the keys next to it were made to weigh by Increment.
+	if t.weight != nil || added {
+		t.reweigh(node)
+	}
+
+	if t.recency {
+		node.entry().touched = t.clock().UnixNano()
+	}
+
+	if t.suffix != nil && added {
+		t.suffix.Add(reverse(key), nil)
+	}
+
+	if t.lru != nil {
+		t.use(node)
+		if added && t.size > t.max {
+			t.evict()
+		}
+	}
+
+	if t.onadd != nil && added {
+		t.onadd(key, node.meta)
+	}
+
+	return node, added
+}
+
+// reweigh updates the weight of the terminator node
+// and the best weights of its ancestors.
+func (t *Trie) reweigh(node *Node) {
+	old := node.best
+	switch {
+	case t.weight != nil:
+		node.best = t.weight(node.meta)
+	case node.ext != nil:
+		node.best = node.ext.weight
+	default:
+		node.best = 0
+	}
+
+	node.propagateBest(old)
+}
+
 // Find and returns node
 func (t *Trie) Find(key string) (*Node, error) {
-	node := t.nodeAtPath(key)
-	node = node.Children()[nul]
-
-	if node == nil || !node.term {
+	key = t.normalize(key)
+	node := t.live(t.terminal(key))
+	if node == nil {
 		err := fmt.Errorf("could not find key: %s in trie", key)
 		return nil, err
 	}
 
+	t.use(node)
+
 	return node, nil
 }
 
+// Contains reports whether key is stored in the trie. Unlike Find
+// it does not allocate.
+func (t *Trie) Contains(key string) bool {
+	key = t.normalize(key)
+	return t.live(t.terminal(key)) != nil
+}
+
+// Get returns the meta of key. The bool is false
+// if key is not stored in the trie.
+func (t *Trie) Get(key string) (interface{}, bool) {
+	node := t.live(t.terminal(t.normalize(key)))
+	if node == nil {
+		return nil, false
+	}
+
+	t.use(node)
+
+	return node.Meta(), true
+}
+
+// SetMeta replaces the meta of key in place. It reports
+// false, and does nothing, if key is not stored in the trie.
+func (t *Trie) SetMeta(key string, meta interface{}) bool {
+	key = t.normalize(key)
+	node := t.live(t.terminal(key))
+	if node == nil {
+		return false
+	}
+
+	t.unindex(key, node.meta)
+	node.meta = meta
+	t.index(key, meta)
+	if t.weight != nil {
+		t.reweigh(node)
+	}
+
+	return true
+}
+
+// FindAll looks up many keys at once and returns the meta of every
+// key which is stored in the trie. Missing keys are absent from the
+// returned map.
+func (t *Trie) FindAll(keys []string) map[string]interface{} {
+	found := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		if node := t.live(t.terminal(t.normalize(key))); node != nil {
+			found[key] = node.Meta()
+			t.use(node)
+		}
+	}
+
+	return found
+}
+
+// LongestPrefix returns the longest key in the trie which is a
+// prefix of query, together with its meta. If no key is a prefix
+// of query, ok is false.
+func (t *Trie) LongestPrefix(query string) (key string, meta interface{}, ok bool) {
+	query = t.normalize(query)
+	end := -1
+	node := t.Root()
+	for i, r := range query {
+		if n := node.children[nul]; n != nil && n.alive() {
+			end, meta = i, n.meta
+		}
+
+		node = node.children[r]
+		if node == nil {
+			break
+		}
+	}
+
+	if node != nil {
+		if n := node.children[nul]; n != nil && n.alive() {
+			end, meta = len(query), n.meta
+		}
+	}
+
+	if end < 0 {
+		return "", nil, false
+	}
+
+	return query[:end], meta, true
+}
+
+// PrefixesOf returns every key in the trie which is a prefix
+// of s, ordered by increasing length.
+func (t *Trie) PrefixesOf(s string) []string {
+	s = t.normalize(s)
+	keys := []string{}
+	node := t.Root()
+	for i, r := range s {
+		if n := node.children[nul]; n != nil && n.alive() {
+			keys = append(keys, s[:i])
+		}
+
+		node = node.children[r]
+		if node == nil {
+			return keys
+		}
+	}
+
+	if n := node.children[nul]; n != nil && n.alive() {
+		keys = append(keys, s)
+	}
+
+	return keys
+}
+
+// terminal returns the terminator node of key,
+// or nil if key is not stored in the trie.
+func (t *Trie) terminal(key string) *Node {
+	node := t.Root()
+	for _, r := range key {
+		n, ok := node.children[r]
+		if !ok {
+			return nil
+		}
+		node = n
+	}
+
+	node, ok := node.children[nul]
+	if !ok || !node.alive() {
+		return nil
+	}
+
+	return node
+}
+
+// Diagnose follows key down the trie as far as it goes. matchedPrefix
+// is the longest prefix of key found in the trie, divergedAt its
+// length in bytes, which is where the first missing rune starts, and
+// node the node of its last rune. If key is stored node is its
+// terminator instead, so that a key which is only a prefix of other
+// keys has a divergedAt of len(key) and a node which isn't a
+// terminator. The key is normalized first, and the results refer to
+// the normalized key.
+func (t *Trie) Diagnose(key string) (matchedPrefix string, divergedAt int, node *Node) {
+	key = t.normalize(key)
+	node = t.Root()
+	for i, r := range key {
+		n, ok := node.children[r]
+		if !ok {
+			return key[:i], i, node
+		}
+		node = n
+	}
+
+	if n, ok := node.children[nul]; ok && n.alive() {
+		node = n
+	}
+
+	return key, len(key), node
+}
+
 // Remove a key from the trie, ensuring that
 // all bitmasks up to root are appropriately recalculated.
-func (t *Trie) Remove(key string) {
-	var (
-		i    int
-		rs   = []rune(key)
-		node = t.nodeAtPath(key)
-	)
+// It reports whether the key was stored in the trie.
+func (t *Trie) Remove(key string) bool {
+	return t.remove(key) != nil
+}
+
+// Pop removes key like Remove and returns the meta it had.
+// The bool reports whether the key was stored in the trie, which
+// it isn't for an expired key, although that is removed as well.
+func (t *Trie) Pop(key string) (interface{}, bool) {
+	expired := t.live(t.terminal(t.normalize(key))) == nil
+	node := t.remove(key)
+	if node == nil || expired {
+		return nil, false
+	}
+
+	return node.Meta(), true
+}
 
+// remove removes key from the trie and returns its terminator
+// node, or nil if key isn't stored.
+func (t *Trie) remove(key string) *Node {
+	key = t.normalize(key)
+	node := t.terminal(key)
+	if node == nil {
+		return nil
+	}
+
+	t.removenode(node, key)
+	return node
+}
+
+// RemoveNode removes the key whose terminator is node, like Remove,
+// without looking the key up. It reports false, and does nothing, if
+// node isn't the terminator of a key stored in t, which is also the
+// case for a node which has been removed already.
+func (t *Trie) RemoveNode(node *Node) bool {
+	if node == nil || !node.alive() || !t.owns(node) {
+		return false
+	}
+
+	t.removenode(node, node.Key())
+	return true
+}
+
+// removenode removes the terminator node of the normalized key,
+// or only decrements its count if it's above one.
+func (t *Trie) removenode(node *Node, key string) {
+	if t.counted {
+		t.total--
+		if node.ext.count > 1 {
+			node.ext.count--
+			return
+		}
+	}
+
+	t.delete(node, key)
+}
+
+// owns reports whether node is still linked into t.
+func (t *Trie) owns(node *Node) bool {
+	for ; node.parent != nil; node = node.parent {
+		if node.parent.children[node.val] != node {
+			return false
+		}
+	}
+
+	return node == t.root
+}
+
+// delete removes the terminator node of the normalized key
+// from the trie, regardless of its count.
+func (t *Trie) delete(node *Node, key string) {
+	unlink(node)
+	t.forget(node, key)
+}
+
+// forget updates the state of t for the terminator
+// node of the normalized key, which is gone.
+func (t *Trie) forget(node *Node, key string) {
+	t.unuse(node)
+	t.unindex(key, node.meta)
 	t.size--
-	for n := node.Parent(); n != nil; n = n.Parent() {
-		i++
-		if len(n.Children()) > 1 {
-			r := rs[len(rs)-i]
-			n.RemoveChild(r)
-			break
+	t.version++
+
+	if t.suffix != nil {
+		t.suffix.Remove(reverse(key))
+	}
+
+	if t.onremove != nil {
+		t.onremove(key, node.meta)
+	}
+}
+
+// Rename moves the meta of oldKey, together with the rest of its
+// state, to newKey. It fails if oldKey isn't stored or newKey already
+// is. Renaming a key to itself does nothing.
+func (t *Trie) Rename(oldKey, newKey string) error {
+	oldKey, newKey = t.normalize(oldKey), t.normalize(newKey)
+	node := t.live(t.terminal(oldKey))
+	if node == nil {
+		return fmt.Errorf("could not find key: %s in trie", oldKey)
+	}
+
+	if oldKey == newKey {
+		return nil
+	}
+
+	if t.live(t.terminal(newKey)) != nil {
+		return fmt.Errorf("key: %s is already in trie", newKey)
+	}
+
+	ext := node.ext
+	t.delete(node, oldKey)
+
+	n, _ := t.put(newKey, func(interface{}, bool) interface{} {
+		return node.meta
+	})
+	if t.counted {
+		// newKey takes over the count of oldKey instead.
+		t.total--
+	}
+
+	if ext != nil {
+		if n.ext != nil {
+			ext.elem = n.ext.elem
+		}
+		n.ext = ext
+	}
+	t.reweigh(n)
+
+	return nil
+}
+
+// RemoveAll removes every key starting with prefix, including
+// prefix itself, and returns how many keys were removed. An empty
+// prefix removes all keys.
+func (t *Trie) RemoveAll(prefix string) int {
+	prefix = t.normalize(prefix)
+	node := t.nodeAtPath(prefix)
+	if node == nil {
+		return 0
+	}
+
+	if t.suffix != nil {
+		collectfunc(node, []rune(prefix), func(key []rune, _ *Node) bool {
+			t.suffix.Remove(reverse(string(key)))
+			return true
+		})
+	}
+
+	if t.counted {
+		collectfunc(node, nil, func(_ []rune, n *Node) bool {
+			t.total -= n.count()
+			return true
+		})
+	}
+
+	var gone []KV
+	if t.onremove != nil {
+		gone = kvs(node, []rune(prefix))
+	}
+
+	if t.lru != nil || t.metas != nil {
+		collectfunc(node, []rune(prefix), func(key []rune, n *Node) bool {
+			t.unuse(n)
+			t.unindex(string(key), n.meta)
+			return true
+		})
+	}
+
+	if t.tombs > 0 {
+		tombcollect(node, nil, func([]rune, *Node) {
+			t.tombs--
+		})
+	}
+
+	removed := countterminals(node)
+	if node.Parent() == nil {
+		node.children = make(map[rune]*Node)
+		node.recalculateMask()
+		node.recalculateBest()
+	} else {
+		unlink(node)
+	}
+
+	t.size -= removed
+	t.version++
+
+	t.removed(gone)
+	return removed
+}
+
+// RemoveFunc removes every key for which pred returns true and
+// returns how many keys were removed. The trie is walked once, in
+// lexicographic order, pruning the branches left without keys.
+func (t *Trie) RemoveFunc(pred func(key string, meta interface{}) bool) int {
+	var gone []KV
+	match := pred
+	if t.onremove != nil {
+		match = func(key string, meta interface{}) bool {
+			ok := pred(key, meta)
+			if ok {
+				gone = append(gone, KV{Key: key, Meta: meta})
+			}
+			return ok
+		}
+	}
+
+	removed := t.removefunc(t.Root(), nil, match)
+	if removed > 0 {
+		t.size -= removed
+		t.version++
+	}
+
+	t.removed(gone)
+	return removed
+}
+
+// Prune removes every key which doesn't start with prefix, so that
+// only the keys below prefix are left, and returns how many keys
+// were removed.
+func (t *Trie) Prune(prefix string) int {
+	prefix = t.normalize(prefix)
+	return t.RemoveFunc(func(key string, _ interface{}) bool {
+		return !strings.HasPrefix(key, prefix)
+	})
+}
+
+func (t *Trie) removefunc(node *Node, pre []rune, pred func(key string, meta interface{}) bool) int {
+	var removed int
+
+	children := node.children
+	for _, r := range sortedrunes(children) {
+		n := children[r]
+		if n.term {
+			key := string(pre)
+			if n.alive() && pred(key, n.Meta()) {
+				delete(children, r)
+				removed++
+				t.unuse(n)
+				t.unindex(key, n.meta)
+				if t.counted {
+					t.total -= n.count()
+				}
+
+				if t.suffix != nil {
+					t.suffix.Remove(reverse(key))
+				}
+			}
+			continue
+		}
+
+		removed += t.removefunc(n, append(pre, r), pred)
+		if !n.HasChildren() {
+			delete(children, r)
 		}
 	}
+
+	if removed > 0 {
+		node.recalculateMask()
+		node.recalculateBest()
+	}
+
+	return removed
+}
+
+// unlink detaches node from the trie, together with every ancestor
+// which is left without children, by removing the branch from the
+// closest ancestor which has other children.
+func unlink(node *Node) {
+	r, n := node.Val(), node.Parent()
+	for n.Parent() != nil && n.ChildCount() == 1 {
+		r, n = n.Val(), n.Parent()
+	}
+
+	n.RemoveChild(r)
 }
 
-// Keys returns all the keys currently stored in the trie.
+// Keys returns all the keys currently stored in the trie
+// in lexicographic order.
 func (t *Trie) Keys() []string {
 	return t.PrefixSearch("")
 }
 
+// HasKeysWithPrefix reports whether any key in the trie
+// starts with pre.
+func (t *Trie) HasKeysWithPrefix(pre string) bool {
+	pre = t.normalize(pre)
+	node := t.nodeAtPath(pre)
+	if node == nil {
+		return false
+	}
+
+	return hasterminal(node)
+}
+
+// CountPrefix returns the number of keys starting with pre.
+func (t *Trie) CountPrefix(pre string) int {
+	pre = t.normalize(pre)
+	node := t.nodeAtPath(pre)
+	if node == nil {
+		return 0
+	}
+
+	return countterminals(node)
+}
+
 // PrefixSearch a prefix search against the keys in the trie.
+// The keys are returned in lexicographic order.
 func (t Trie) PrefixSearch(pre string) []string {
+	return t.PrefixSearchN(pre, 0)
+}
+
+// PrefixSearchN is like PrefixSearch but stops the traversal as
+// soon as n keys have been found. A value of n <= 0 means no limit.
+func (t Trie) PrefixSearchN(pre string, n int) []string {
+	pre = t.normalize(pre)
+	var keys []string
+
+	node := t.nodeAtPath(pre)
+	if node == nil {
+		return keys
+	}
+
+	collect(node, []rune(pre), &keys, n)
+	return keys
+}
+
+// PrefixSearchMaxLen is like PrefixSearch but only returns keys
+// which are at most maxExtra runes longer than pre. A negative
+// maxExtra means no limit.
+func (t *Trie) PrefixSearchMaxLen(pre string, maxExtra int) []string {
+	pre = t.normalize(pre)
 	var keys []string
 
 	node := t.nodeAtPath(pre)
@@ -159,10 +1152,212 @@ func (t Trie) PrefixSearch(pre string) []string {
 		return keys
 	}
 
-	collect(node, []rune(pre), &keys)
+	collectdepth(node, []rune(pre), maxExtra, &keys)
+	return keys
+}
+
+// FuzzySearch performs a fuzzy search against the keys in the trie
+// and returns every key which contains partial as a subsequence.
+// The keys are returned in lexicographic order.
+func (t *Trie) FuzzySearch(partial string) []string {
+	return t.FuzzySearchN(partial, 0)
+}
+
+// FuzzySearchN is like FuzzySearch but stops the traversal as soon
+// as n keys have been found. A value of n <= 0 means no limit.
+func (t *Trie) FuzzySearchN(partial string, n int) []string {
+	partial = t.normalize(partial)
+	var keys []string
+	fuzzycollect(t.Root(), nil, []rune(partial), fuzzymatch{}, nil, func(key []rune, _ *Node, _ fuzzymatch) bool {
+		keys = append(keys, string(key))
+		return n <= 0 || len(keys) < n
+	})
+	return keys
+}
+
+// KV is a key stored in the trie together with its meta.
+type KV struct {
+	Key  string
+	Meta interface{}
+}
+
+// PrefixSearchWithMeta is like PrefixSearch but returns the meta
+// of every key along with it.
+func (t *Trie) PrefixSearchWithMeta(pre string) []KV {
+	pre = t.normalize(pre)
+	var kvs []KV
+
+	node := t.nodeAtPath(pre)
+	if node == nil {
+		return kvs
+	}
+
+	collectfunc(node, []rune(pre), func(key []rune, n *Node) bool {
+		kvs = append(kvs, KV{Key: string(key), Meta: n.Meta()})
+		return true
+	})
+	return kvs
+}
+
+// PrefixSearchNodes is like PrefixSearch but returns the terminator
+// node of every key, which is the node carrying its meta.
+func (t *Trie) PrefixSearchNodes(pre string) []*Node {
+	pre = t.normalize(pre)
+	var nodes []*Node
+
+	node := t.nodeAtPath(pre)
+	if node == nil {
+		return nodes
+	}
+
+	collectfunc(node, []rune(pre), func(_ []rune, n *Node) bool {
+		nodes = append(nodes, n)
+		return true
+	})
+	return nodes
+}
+
+// Range returns all keys k with from <= k < to in lexicographic
+// order. An empty from means from the first key, and an empty to
+// means up to and including the last key.
+func (t *Trie) Range(from, to string) []string {
+	from, to = t.normalize(from), t.normalize(to)
+	var keys []string
+	rangecollect(t.Root(), nil, []rune(from), []rune(to), true, to != "", &keys, 0)
 	return keys
 }
 
+// AfterEmptyKey is the cursor PrefixSearchPage returns as next when
+// the last key of a page is the empty key, as an empty next means
+// that there is no following page. It holds the nul rune, so it can't
+// be mistaken for a key.
+const AfterEmptyKey = "\x00"
+
+// PrefixSearchPage returns a page of at most n keys starting with
+// pre, in lexicographic order, beginning just past the key after. An
+// empty after starts with the first key. next is the last key of the
+// page, to pass as after to get the following page, or empty on the
+// last page. If the last key of the page is the empty key, next is
+// AfterEmptyKey instead.
+func (t *Trie) PrefixSearchPage(pre string, after string, n int) (results []string, next string) {
+	cursor := after != ""
+	if after == AfterEmptyKey {
+		after = ""
+	}
+	pre, after = t.normalize(pre), t.normalize(after)
+
+	node := t.nodeAtPath(pre)
+	if node == nil || n <= 0 {
+		return results, ""
+	}
+
+	lo := cursor && strings.HasPrefix(after, pre)
+	if cursor && !lo && after > pre {
+		return results, ""
+	}
+
+	// The cursor, which is skipped if it is still stored, and one
+	// more key than needed, which tells whether a page follows.
+	rangecollect(node, []rune(pre), []rune(after), nil, lo, false, &results, n+2)
+	if lo && len(results) > 0 && results[0] == after {
+		results = results[1:]
+	}
+
+	if len(results) > n {
+		results = results[:n]
+		if next = results[n-1]; next == "" {
+			next = AfterEmptyKey
+		}
+	}
+
+	return results, next
+}
+
+// LongestCommonPrefix returns the longest prefix shared by
+// all keys in the trie.
+func (t *Trie) LongestCommonPrefix() string {
+	return t.LongestCommonPrefixUnder("")
+}
+
+// LongestCommonPrefixUnder returns the longest prefix shared by all
+// keys starting with pre, or an empty string if there are none.
+func (t *Trie) LongestCommonPrefixUnder(pre string) string {
+	pre = t.normalize(pre)
+
+	node := t.nodeAtPath(pre)
+	if node == nil {
+		return ""
+	}
+
+	// The keys in between share every prefix the smallest
+	// and the largest key share.
+	lo, ok := boundkey(node, []rune(pre), false)
+	if !ok {
+		return ""
+	}
+	hi, _ := boundkey(node, []rune(pre), true)
+
+	var i int
+	for i < len(lo) && i < len(hi) && lo[i] == hi[i] {
+		i++
+	}
+
+	return string(lo[:i])
+}
+
+// Min returns the lexicographically smallest key in the trie.
+// The bool is false if the trie is empty.
+func (t *Trie) Min() (string, bool) {
+	key, ok := boundkey(t.Root(), nil, false)
+	return string(key), ok
+}
+
+// Max returns the lexicographically largest key in the trie.
+// The bool is false if the trie is empty.
+func (t *Trie) Max() (string, bool) {
+	key, ok := boundkey(t.Root(), nil, true)
+	return string(key), ok
+}
+
+// boundkey returns the smallest key below node, or the largest if
+// max is true. The bool is false if there are no keys below node.
+func boundkey(node *Node, pre []rune, max bool) ([]rune, bool) {
+	rs := sortedrunes(node.children)
+	for i := range rs {
+		r := rs[i]
+		if max {
+			r = rs[len(rs)-1-i]
+		}
+
+		n := node.children[r]
+		if n.term {
+			if n.alive() {
+				return pre, true
+			}
+			continue
+		}
+
+		if key, ok := boundkey(n, append(pre, r), max); ok {
+			return key, true
+		}
+	}
+
+	return nil, false
+}
+
+// normalize returns key the way it is stored in the trie.
+func (t *Trie) normalize(key string) string {
+	if t.norm != nil {
+		key = t.norm(key)
+	}
+
+	if t.fold {
+		key = strings.Map(unicode.ToLower, key)
+	}
+
+	return key
+}
+
 func (t Trie) nodeAtPath(pre string) *Node {
 	runes := []rune(pre)
 	return findNode(t.Root(), runes)
@@ -177,7 +1372,7 @@ func findNode(node *Node, runes []rune) *Node {
 		return node
 	}
 
-	n, ok := node.Children()[runes[0]]
+	n, ok := node.children[runes[0]]
 	if !ok {
 		return nil
 	}
@@ -192,13 +1387,18 @@ func findNode(node *Node, runes []rune) *Node {
 	return findNode(n, nrunes)
 }
 
-func (t Trie) addrune(node *Node, runes []rune, i int) *Node {
+// addrune returns the terminator node of runes below node, creating
+// the missing nodes. The bool reports whether the terminator is new.
+func (t Trie) addrune(node *Node, runes []rune, i int) (*Node, bool) {
 	if len(runes) == 0 {
-		return node.NewChild(0, 0, nul, true)
+		if n, ok := node.children[nul]; ok && n.term {
+			return n, false
+		}
+		return node.NewChild(0, 0, nul, true), true
 	}
 
 	r := runes[0]
-	c := node.Children()
+	c := node.children
 
 	n, ok := c[r]
 	bitmask := maskruneslice(runes)
@@ -225,44 +1425,219 @@ func maskrune(r rune) uint64 {
 	return i << (uint64(r) - 97)
 }
 
-func collect(node *Node, pre []rune, keys *[]string) {
-	children := node.Children()
-	for r, n := range children {
+// collect appends every key below node to keys in lexicographic
+// order. It stops as soon as limit keys have been collected, in which
+// case false is returned. A limit <= 0 means no limit.
+func collect(node *Node, pre []rune, keys *[]string, limit int) bool {
+	return collectfunc(node, pre, func(key []rune, _ *Node) bool {
+		*keys = append(*keys, string(key))
+		return limit <= 0 || len(*keys) < limit
+	})
+}
+
+// collectdepth is like collect but does not descend more than
+// left runes below node. A negative left means no limit.
+func collectdepth(node *Node, pre []rune, left int, keys *[]string) {
+	children := node.children
+	for _, r := range sortedrunes(children) {
+		n := children[r]
+		if n.term {
+			if n.alive() {
+				*keys = append(*keys, string(pre))
+			}
+			continue
+		}
+
+		if left == 0 {
+			continue
+		}
+
+		collectdepth(n, append(pre, r), left-1, keys)
+	}
+}
+
+// collectfunc calls fn with every key below node and its terminator
+// node in lexicographic order. It stops as soon as fn returns false,
+// in which case false is returned. The key passed to fn is only valid
+// until fn returns.
+func collectfunc(node *Node, pre []rune, fn func(key []rune, term *Node) bool) bool {
+	return cancelcollect(node, pre, nil, fn)
+}
+
+// cancelcollect is like collectfunc but also stops once c is
+// cancelled, which is checked at every node visited.
+func cancelcollect(node *Node, pre []rune, c *canceller, fn func(key []rune, term *Node) bool) bool {
+	children := node.children
+	for _, r := range sortedrunes(children) {
+		n := children[r]
+		if c.cancelled() {
+			return false
+		}
+
 		if n.term {
-			*keys = append(*keys, string(pre))
+			if n.alive() && !fn(pre, n) {
+				return false
+			}
 			continue
 		}
 
 		npre := append(pre, r)
-		collect(n, npre, keys)
+		if !cancelcollect(n, npre, c, fn) {
+			return false
+		}
 	}
+
+	return true
 }
 
-func fuzzycollect(node *Node, partialmatch, partial []rune, keys *[]string) {
+// rangecollect appends the keys below node which are within the
+// bounds from and to. lo and hi report whether pre is still equal
+// to the beginning of from and to, in which case the respective
+// bound has to be checked; otherwise the whole branch is in range.
+// Like collect it stops once limit keys have been collected.
+func rangecollect(node *Node, pre, from, to []rune, lo, hi bool, keys *[]string, limit int) bool {
+	if !lo && !hi {
+		return collect(node, pre, keys, limit)
+	}
+
+	depth := len(pre)
+	children := node.children
+	for _, r := range sortedrunes(children) {
+		n := children[r]
+		if n.term {
+			if n.alive() && (!lo || depth == len(from)) && (!hi || depth < len(to)) {
+				*keys = append(*keys, string(pre))
+				if limit > 0 && len(*keys) >= limit {
+					return false
+				}
+			}
+			continue
+		}
+
+		nlo, nhi := lo, hi
+		if lo && depth < len(from) {
+			if r < from[depth] {
+				continue
+			}
+			nlo = r == from[depth]
+		} else {
+			nlo = false
+		}
+
+		if hi {
+			if depth >= len(to) || r > to[depth] {
+				return false
+			}
+			nhi = r == to[depth]
+		}
+
+		if !rangecollect(n, append(pre, r), from, to, nlo, nhi, keys, limit) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// sortedrunes returns the runes of children in ascending order,
+// so the terminator always comes first.
+func sortedrunes(children map[rune]*Node) []rune {
+	rs := make([]rune, 0, len(children))
+	for r := range children {
+		rs = append(rs, r)
+	}
+
+	sort.Slice(rs, func(i, j int) bool { return rs[i] < rs[j] })
+	return rs
+}
+
+func countterminals(node *Node) int {
+	var c int
+	for _, n := range node.children {
+		if n.term {
+			if n.alive() {
+				c++
+			}
+			continue
+		}
+		c += countterminals(n)
+	}
+
+	return c
+}
+
+// record notes a match at position i of the key.
+func (fm *fuzzymatch) record(i int) {
+	if fm.matched == 0 {
+		fm.first = i
+	} else if i == fm.last+1 {
+		fm.adjacent++
+	}
+
+	fm.last = i
+	fm.matched++
+}
+
+func hasterminal(node *Node) bool {
+	for _, n := range node.children {
+		if n.alive() || hasterminal(n) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fuzzymatch records where the runes of a fuzzy search
+// query have been matched in a key.
+type fuzzymatch struct {
+	matched  int
+	first    int
+	last     int
+	adjacent int
+}
+
+// fuzzycollect calls fn with every key below node which contains
+// partial as a subsequence in lexicographic order, until fn
+// returns false. The runes of partial are matched at their leftmost
+// occurrence, which is recorded in fm.
+func fuzzycollect(node *Node, partialmatch, partial []rune, fm fuzzymatch, c *canceller, fn func(key []rune, term *Node, fm fuzzymatch) bool) bool {
+	if c.cancelled() {
+		return false
+	}
+
 	partiallen := len(partial)
 
 	if partiallen == 0 {
-		collect(node, partialmatch, keys)
-		return
+		return cancelcollect(node, partialmatch, c, func(key []rune, n *Node) bool {
+			return fn(key, n, fm)
+		})
 	}
 
 	m := maskruneslice(partial)
-	children := node.Children()
-	for v, n := range children {
+	children := node.children
+	for _, v := range sortedrunes(children) {
+		n := children[v]
 		xor := n.Mask() ^ m
 		if (xor & m) != 0 {
 			continue
 		}
 
-		npartial := partial
+		npartial, nfm := partial, fm
 		if v == partial[0] {
 			if partiallen > 1 {
 				npartial = partial[1:]
 			} else {
 				npartial = partial[0:0]
 			}
+
+			nfm.record(len(partialmatch))
 		}
 
-		fuzzycollect(n, append(partialmatch, v), npartial, keys)
+		if !fuzzycollect(n, append(partialmatch, v), npartial, nfm, c, fn) {
+			return false
+		}
 	}
+
+	return true
 }
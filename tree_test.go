@@ -0,0 +1,426 @@
+package trie
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func pages(trie *Trie, pre string, n int) [][]string {
+	var res [][]string
+	after := ""
+	for i := 0; i < 100; i++ {
+		page, next := trie.PrefixSearchPage(pre, after, n)
+		res = append(res, page)
+		if next == "" {
+			return res
+		}
+		after = next
+	}
+
+	return res
+}
+
+func TestPrefixSearchPage(t *testing.T) {
+	trie := NewTrie()
+	for _, key := range []string{"", "a", "ab", "abc", "b", "ba"} {
+		trie.Add(key, nil)
+	}
+
+	tests := []struct {
+		pre  string
+		n    int
+		want [][]string
+	}{
+		{"", 1, [][]string{{""}, {"a"}, {"ab"}, {"abc"}, {"b"}, {"ba"}}},
+		{"", 4, [][]string{{"", "a", "ab", "abc"}, {"b", "ba"}}},
+		{"", 6, [][]string{{"", "a", "ab", "abc", "b", "ba"}}},
+		{"a", 2, [][]string{{"a", "ab"}, {"abc"}}},
+		{"x", 2, [][]string{nil}},
+	}
+
+	for _, tt := range tests {
+		if got := pages(trie, tt.pre, tt.n); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("pages of %q by %d: expected %v, got %v", tt.pre, tt.n, tt.want, got)
+		}
+	}
+}
+
+func TestPrefixSearchPageCursor(t *testing.T) {
+	trie := NewTrie()
+	for _, key := range []string{"", "a", "ab", "b"} {
+		trie.Add(key, nil)
+	}
+
+	if page, next := trie.PrefixSearchPage("", "", 1); !reflect.DeepEqual(page, []string{""}) || next != AfterEmptyKey {
+		t.Errorf("expected [\"\"] and AfterEmptyKey, got %q and %q", page, next)
+	}
+	if page, next := trie.PrefixSearchPage("", AfterEmptyKey, 2); !reflect.DeepEqual(page, []string{"a", "ab"}) || next != "ab" {
+		t.Errorf("expected [a ab] and ab, got %q and %q", page, next)
+	}
+
+	// The cursor doesn't have to be stored anymore, or at all.
+	trie.Remove("ab")
+	if page, next := trie.PrefixSearchPage("", "ab", 2); !reflect.DeepEqual(page, []string{"b"}) || next != "" {
+		t.Errorf("expected [b] as the last page, got %q and %q", page, next)
+	}
+	if page, _ := trie.PrefixSearchPage("a", "", 2); !reflect.DeepEqual(page, []string{"a"}) {
+		t.Errorf("expected [a], got %q", page)
+	}
+	if page, _ := trie.PrefixSearchPage("a", "a", 2); len(page) != 0 {
+		t.Errorf("expected nothing after a, got %q", page)
+	}
+	if page, _ := trie.PrefixSearchPage("b", "a", 2); !reflect.DeepEqual(page, []string{"b"}) {
+		t.Errorf("expected [b] after a cursor before the prefix, got %q", page)
+	}
+}
+
+func TestChildrenCopy(t *testing.T) {
+	trie := NewTrie()
+	trie.Add("foo", nil)
+	trie.Add("bar", nil)
+
+	children := trie.Root().Children()
+	delete(children, 'f')
+	children['x'] = &Node{}
+
+	if keys := trie.Keys(); !reflect.DeepEqual(keys, []string{"bar", "foo"}) {
+		t.Errorf("expected [bar foo], got %v", keys)
+	}
+	if rs := trie.Root().ChildRunes(); !reflect.DeepEqual(rs, []rune{'b', 'f'}) {
+		t.Errorf("expected [b f], got %q", rs)
+	}
+	if _, ok := trie.Root().Child('f'); !ok {
+		t.Error("expected f to still be a child of the root")
+	}
+}
+
+func TestFuzzySearch(t *testing.T) {
+	trie := NewTrie()
+	for _, key := range []string{"foo", "foobar", "fxo", "bar", "xyz"} {
+		trie.Add(key, nil)
+	}
+
+	if keys := trie.FuzzySearch("fo"); !reflect.DeepEqual(keys, []string{"foo", "foobar", "fxo"}) {
+		t.Errorf("expected [foo foobar fxo], got %v", keys)
+	}
+	if keys := trie.FuzzySearch(""); !reflect.DeepEqual(keys, trie.Keys()) {
+		t.Errorf("expected every key for an empty query, got %v", keys)
+	}
+	if keys := trie.FuzzySearch("foobarbaz"); len(keys) != 0 {
+		t.Errorf("expected no keys for a query longer than any key, got %v", keys)
+	}
+}
+
+func TestFuzzySearchPruning(t *testing.T) {
+	trie := NewTrie()
+	trie.Add("foo", nil)
+	trie.Add("xyz", nil)
+
+	// Descending into the branch of xyz, which shares no
+	// letter with the query, would hit the nil node.
+	trie.Root().children['x'].children['y'] = nil
+
+	if keys := trie.FuzzySearch("fo"); !reflect.DeepEqual(keys, []string{"foo"}) {
+		t.Errorf("expected [foo], got %v", keys)
+	}
+}
+
+func TestContains(t *testing.T) {
+	trie := NewTrie()
+	trie.Add("", nil)
+	trie.Add("foobar", nil)
+
+	for _, key := range []string{"", "foo", "foobar", "foobarx", "x"} {
+		_, err := trie.Find(key)
+		if ok := trie.Contains(key); ok != (err == nil) {
+			t.Errorf("expected Contains(%q) to agree with Find, got %t", key, ok)
+		}
+	}
+
+	if allocs := testing.AllocsPerRun(100, func() {
+		trie.Contains("foobar")
+		trie.Contains("foo")
+	}); allocs != 0 {
+		t.Errorf("expected no allocations, got %v", allocs)
+	}
+}
+
+func BenchmarkContainsMiss(b *testing.B) {
+	trie := NewTrie()
+	trie.Add("foobar", nil)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		trie.Contains("foo")
+	}
+}
+
+func BenchmarkFindMiss(b *testing.B) {
+	trie := NewTrie()
+	trie.Add("foobar", nil)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		trie.Find("foo")
+	}
+}
+
+func TestRange(t *testing.T) {
+	trie := NewTrie()
+	for _, key := range []string{"", "a", "ab", "abc", "b", "ba", "c"} {
+		trie.Add(key, nil)
+	}
+
+	tests := []struct {
+		from, to string
+		want     []string
+	}{
+		{"", "", []string{"", "a", "ab", "abc", "b", "ba", "c"}},
+		{"ab", "b", []string{"ab", "abc"}},
+		{"ab", "ba", []string{"ab", "abc", "b"}},
+		{"a", "", []string{"a", "ab", "abc", "b", "ba", "c"}},
+		{"", "a", []string{""}},
+		{"abc", "abc", nil},
+		{"aa", "bb", []string{"ab", "abc", "b", "ba"}},
+		{"c", "", []string{"c"}},
+	}
+
+	for _, tt := range tests {
+		if keys := trie.Range(tt.from, tt.to); !reflect.DeepEqual(keys, tt.want) {
+			t.Errorf("expected %v for [%q, %q), got %v", tt.want, tt.from, tt.to, keys)
+		}
+	}
+}
+
+func TestFuzzySearchDeterministic(t *testing.T) {
+	trie := NewTrie()
+	for _, key := range []string{"zfoo", "foo", "afxo", "fo", "xfyo", "bfo"} {
+		trie.Add(key, nil)
+	}
+
+	want := []string{"afxo", "bfo", "fo", "foo", "xfyo", "zfoo"}
+	for i := 0; i < 50; i++ {
+		if keys := trie.FuzzySearch("fo"); !reflect.DeepEqual(keys, want) {
+			t.Fatalf("expected %v, got %v", want, keys)
+		}
+		if keys := trie.FuzzySearchN("fo", 2); !reflect.DeepEqual(keys, want[:2]) {
+			t.Fatalf("expected %v, got %v", want[:2], keys)
+		}
+	}
+}
+
+func batch(n int) (*Trie, []string) {
+	trie := NewTrie()
+	keys := make([]string, 0, 2*n)
+	for i := 0; i < n; i++ {
+		key := fmt.Sprintf("candidate%05d", i)
+		if i%2 == 0 {
+			trie.Add(key, i)
+		}
+		keys = append(keys, key)
+	}
+
+	return trie, keys
+}
+
+func TestFindAll(t *testing.T) {
+	trie, keys := batch(10)
+
+	found := trie.FindAll(keys)
+	if len(found) != 5 {
+		t.Errorf("expected the 5 stored keys, got %v", found)
+	}
+	for i, key := range keys {
+		if m, ok := found[key]; ok != (i%2 == 0) || (ok && m != i) {
+			t.Errorf("expected %s to be found with %d only if stored, got %v", key, i, m)
+		}
+	}
+}
+
+func BenchmarkFindAll(b *testing.B) {
+	trie, keys := batch(10000)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		trie.FindAll(keys)
+	}
+}
+
+func BenchmarkFindLoop(b *testing.B) {
+	trie, keys := batch(10000)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		found := make(map[string]interface{}, len(keys))
+		for _, key := range keys {
+			if n, err := trie.Find(key); err == nil {
+				found[key] = n.Meta()
+			}
+		}
+	}
+}
+
+func TestLenRandomized(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	trie := NewTrie()
+	keys := make(map[string]bool)
+
+	for i := 0; i < 5000; i++ {
+		b := make([]byte, rnd.Intn(4))
+		for j := range b {
+			b[j] = "abc"[rnd.Intn(3)]
+		}
+		key := string(b)
+
+		if rnd.Intn(3) == 0 {
+			if removed := trie.Remove(key); removed != keys[key] {
+				t.Fatalf("expected Remove(%q) to report %t", key, keys[key])
+			}
+			delete(keys, key)
+		} else {
+			trie.Add(key, nil)
+			keys[key] = true
+		}
+
+		if trie.Len() != len(keys) || trie.Len() != len(trie.Keys()) {
+			t.Fatalf("expected %d keys, got Len %d and %d from Keys", len(keys), trie.Len(), len(trie.Keys()))
+		}
+		if trie.Empty() != (len(keys) == 0) {
+			t.Fatalf("expected Empty to be %t", len(keys) == 0)
+		}
+	}
+}
+
+func TestUpsert(t *testing.T) {
+	trie := NewTrie()
+	sum := func(old, new interface{}) interface{} { return old.(int) + new.(int) }
+
+	trie.Upsert("foo", 1, sum)
+	trie.Upsert("foo", 2, sum)
+	if m, _ := trie.Get("foo"); m != 3 || trie.Len() != 1 {
+		t.Errorf("expected foo with 3 as the only key, got %v and %d keys", m, trie.Len())
+	}
+
+	trie.Upsert("foo", 4, func(old, new interface{}) interface{} { return nil })
+	if m, ok := trie.Get("foo"); !ok || m != nil || trie.Len() != 1 {
+		t.Errorf("expected foo to stay with a nil meta, got %v, %t and %d keys", m, ok, trie.Len())
+	}
+}
+
+func TestUpsertPanic(t *testing.T) {
+	trie := NewTrie()
+	trie.Add("foo", 1)
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected the panic of merge to propagate")
+			}
+		}()
+
+		trie.Upsert("foo", 2, func(old, new interface{}) interface{} { panic("merge") })
+	}()
+
+	if m, _ := trie.Get("foo"); m != 1 || trie.Len() != 1 {
+		t.Errorf("expected foo to be left with 1, got %v and %d keys", m, trie.Len())
+	}
+}
+
+func TestSetMeta(t *testing.T) {
+	trie := NewTrie()
+	trie.Add("foo", 1)
+	trie.Add("foobar", 2)
+	node, _ := trie.Find("foo")
+	mask := trie.Root().Mask()
+
+	if !trie.SetMeta("foo", 10) {
+		t.Fatal("expected the meta of foo to be set")
+	}
+	if trie.SetMeta("fo", 10) {
+		t.Error("expected no meta to be set for a key which isn't stored")
+	}
+
+	if m, _ := trie.Get("foo"); m != 10 {
+		t.Errorf("expected 10 for foo, got %v", m)
+	}
+	if n, _ := trie.Find("foo"); n != node {
+		t.Error("expected the terminator of foo to be kept")
+	}
+	if trie.Len() != 2 || trie.Root().Mask() != mask {
+		t.Errorf("expected 2 keys and an unchanged mask, got %d keys", trie.Len())
+	}
+}
+
+func TestAddCheckedNul(t *testing.T) {
+	trie := NewTrie()
+	trie.Add("ab", nil)
+
+	if _, err := trie.AddChecked("a\x00b", nil); err != ErrNulKey {
+		t.Errorf("expected ErrNulKey, got %v", err)
+	}
+	if trie.Contains("a") || trie.Contains("a\x00b") {
+		t.Error("expected the rejected key to leave no trace")
+	}
+	if keys := trie.Keys(); !reflect.DeepEqual(keys, []string{"ab"}) || trie.Len() != 1 {
+		t.Errorf("expected [ab], got %q", keys)
+	}
+
+	if _, err := trie.AddChecked("a", nil); err != nil {
+		t.Errorf("expected a to be added, got %v", err)
+	}
+}
+
+func TestTerminal(t *testing.T) {
+	trie := NewTrie()
+	trie.Add("a", nil)
+	trie.Add("ab", nil)
+
+	var terminals []string
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		if n.Terminal() {
+			terminals = append(terminals, n.Key())
+			if n.Val() != nul || n.HasChildren() {
+				t.Errorf("expected a terminator to be a nul leaf, got %v", n)
+			}
+		}
+		for _, r := range n.ChildRunes() {
+			c, _ := n.Child(r)
+			walk(c)
+		}
+	}
+	walk(trie.Root())
+
+	if !reflect.DeepEqual(terminals, []string{"a", "ab"}) {
+		t.Errorf("expected the terminators of a and ab, got %v", terminals)
+	}
+	if n, _ := trie.Root().Child('a'); n.Terminal() {
+		t.Error("expected the node of the rune a not to be a terminator")
+	}
+}
+
+func TestHasRune(t *testing.T) {
+	trie := NewTrie()
+	trie.Add("bat", nil)
+	trie.Add("cow", nil)
+	node, _ := trie.Root().Child('b')
+
+	for r := 'a'; r <= 'z'; r++ {
+		want := r == 'a' || r == 'b' || r == 't'
+		if got := node.HasRune(r); got != want {
+			t.Errorf("expected HasRune(%q) to be %t below b", r, want)
+		}
+		if got := trie.Root().HasRune(r); got != (want || r == 'c' || r == 'o' || r == 'w') {
+			t.Errorf("expected HasRune(%q) of the root to be %t", r, !got)
+		}
+	}
+
+	// The mask has no room for these, so HasRune can't rule them out.
+	for _, r := range []rune{'A', '1', 'é', '€'} {
+		if !node.HasRune(r) {
+			t.Errorf("expected HasRune(%q) to be true for a rune outside the mask", r)
+		}
+	}
+}
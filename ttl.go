@@ -0,0 +1,71 @@
+package trie
+
+import "time"
+
+// AddTTL is like Add but makes the key expire once ttl has passed.
+// The lookups of a single key, like Find, Get, Contains, FindAll,
+// Count, Weight and Metas, report an expired key as absent, and so
+// do SetMeta, CompareAndSwapMeta, RemoveMeta, Touch, Pop and Rename.
+// Adding an expired key again, with Add, AddNew, GetOrAdd, Upsert,
+// AppendMeta or Increment, stores it as a new key. The key stays in
+// the trie, though, and is still counted by Len, listed by the
+// searches and removed by Remove, until Sweep removes it or it is
+// added again. Keys added with Add never expire.
+func (t *Trie) AddTTL(key string, meta interface{}, ttl time.Duration) *Node {
+	node := t.Add(key, meta)
+	node.entry().expires = t.clock().Add(ttl).UnixNano()
+	return node
+}
+
+// Sweep removes every expired key from the trie and returns
+// how many keys were removed.
+func (t *Trie) Sweep() int {
+	now := t.clock().UnixNano()
+
+	var (
+		nodes []*Node
+		keys  []string
+	)
+	collectfunc(t.Root(), nil, func(key []rune, n *Node) bool {
+		if expired(n, now) {
+			nodes = append(nodes, n)
+			keys = append(keys, string(key))
+		}
+		return true
+	})
+
+	for i, n := range nodes {
+		if t.counted {
+			t.total -= n.count()
+		}
+		t.delete(n, keys[i])
+	}
+
+	return len(nodes)
+}
+
+// live returns the terminator node n, or nil if its key has expired.
+func (t *Trie) live(n *Node) *Node {
+	if n == nil || n.ext == nil || n.ext.expires == 0 {
+		return n
+	}
+
+	if expired(n, t.clock().UnixNano()) {
+		return nil
+	}
+
+	return n
+}
+
+// clock returns the current time.
+func (t *Trie) clock() time.Time {
+	if t.now != nil {
+		return t.now()
+	}
+
+	return time.Now()
+}
+
+func expired(n *Node, now int64) bool {
+	return n.ext != nil && n.ext.expires != 0 && n.ext.expires <= now
+}
@@ -0,0 +1,181 @@
+package trie
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func TestAddTTL(t *testing.T) {
+	clock := &testClock{now: time.Unix(1000, 0)}
+	trie := NewTrie(WithClock(clock.Now))
+	trie.AddTTL("short", 1, time.Second)
+	trie.AddTTL("long", 2, time.Hour)
+	trie.Add("forever", 3)
+
+	clock.now = clock.now.Add(time.Minute)
+
+	if trie.Contains("short") {
+		t.Error("expected short to have expired")
+	}
+	if _, err := trie.Find("short"); err == nil {
+		t.Error("expected Find to miss short")
+	}
+	if _, ok := trie.Get("short"); ok {
+		t.Error("expected Get to miss short")
+	}
+	for _, key := range []string{"long", "forever"} {
+		if !trie.Contains(key) {
+			t.Errorf("expected %s to be stored", key)
+		}
+	}
+
+	if trie.Len() != 3 {
+		t.Errorf("expected expired keys to be counted until swept, got %d", trie.Len())
+	}
+
+	if n := trie.Sweep(); n != 1 {
+		t.Errorf("expected Sweep to remove 1 key, got %d", n)
+	}
+	if trie.Len() != 2 {
+		t.Errorf("expected 2 keys after Sweep, got %d", trie.Len())
+	}
+}
+
+func TestExpiredLookups(t *testing.T) {
+	clock := &testClock{now: time.Unix(1000, 0)}
+	trie := NewTrie(WithClock(clock.Now))
+	trie.AddTTL("k", 1, time.Second)
+	trie.Increment("k", 2)
+	clock.now = clock.now.Add(time.Minute)
+
+	if found := trie.FindAll([]string{"k"}); len(found) != 0 {
+		t.Errorf("expected FindAll to miss k, got %v", found)
+	}
+	if n := trie.Count("k"); n != 0 {
+		t.Errorf("expected count 0, got %d", n)
+	}
+	if w := trie.Weight("k"); w != 0 {
+		t.Errorf("expected weight 0, got %v", w)
+	}
+	if metas := trie.Metas("k"); metas != nil {
+		t.Errorf("expected no metas, got %v", metas)
+	}
+	if trie.SetMeta("k", 2) || trie.CompareAndSwapMeta("k", 1, 2) {
+		t.Error("expected the meta of k not to change")
+	}
+}
+
+func TestAddClearsTTL(t *testing.T) {
+	clock := &testClock{now: time.Unix(1000, 0)}
+	trie := NewTrie(WithClock(clock.Now))
+	trie.AddTTL("k", 1, time.Second)
+	trie.Add("k", 2)
+	clock.now = clock.now.Add(time.Minute)
+
+	if !trie.Contains("k") {
+		t.Error("expected Add to clear the expiry of k")
+	}
+	if n := trie.Sweep(); n != 0 {
+		t.Errorf("expected Sweep to remove nothing, got %d", n)
+	}
+}
+
+// expiredTrie returns a trie holding k with the meta "old",
+// which has expired by the time it is returned.
+func expiredTrie(opts ...Option) *Trie {
+	clock := &testClock{now: time.Unix(1000, 0)}
+	trie := NewTrie(append(opts, WithClock(clock.Now))...)
+	trie.AddTTL("k", "old", time.Second)
+	clock.now = clock.now.Add(time.Minute)
+	return trie
+}
+
+func TestAddExpired(t *testing.T) {
+	var added []string
+	trie := expiredTrie(OnAdd(func(key string, _ interface{}) {
+		added = append(added, key)
+	}))
+
+	if _, ok := trie.AddNew("k", "new"); !ok {
+		t.Error("expected AddNew to report an expired key as new")
+	}
+	if m, ok := trie.Get("k"); !ok || m != "new" {
+		t.Errorf("expected k with new, got %v, %t", m, ok)
+	}
+	if len(added) != 2 || trie.Len() != 1 {
+		t.Errorf("expected OnAdd for both adds and 1 key, got %v and %d", added, trie.Len())
+	}
+
+	trie = expiredTrie()
+	if m, ok := trie.GetOrAdd("k", "new"); ok || m != "new" {
+		t.Errorf("expected GetOrAdd to add new, got %v, %t", m, ok)
+	}
+	if !trie.Contains("k") {
+		t.Error("expected GetOrAdd to store k again")
+	}
+
+	trie = expiredTrie()
+	trie.Upsert("k", "new", func(old, new interface{}) interface{} {
+		t.Errorf("expected no merge with the expired %v", old)
+		return new
+	})
+	if m, _ := trie.Get("k"); m != "new" {
+		t.Errorf("expected Upsert to store new, got %v", m)
+	}
+}
+
+func TestAppendMetaExpired(t *testing.T) {
+	trie := expiredTrie()
+	trie.AppendMeta("k", "more")
+
+	if n := trie.AppendMeta("k", "new"); n != 2 {
+		t.Errorf("expected 2 metas, got %d", n)
+	}
+	if metas := trie.Metas("k"); !reflect.DeepEqual(metas, []interface{}{"more", "new"}) {
+		t.Errorf("expected [more new], got %v", metas)
+	}
+}
+
+func TestIncrementExpired(t *testing.T) {
+	trie := expiredTrie()
+
+	if w := trie.Increment("k", 3); w != 3 {
+		t.Errorf("expected a weight of 3, got %v", w)
+	}
+	if w := trie.Weight("k"); w != 3 {
+		t.Errorf("expected Weight to see 3, got %v", w)
+	}
+}
+
+func TestPopRenameExpired(t *testing.T) {
+	trie := expiredTrie()
+	if m, ok := trie.Pop("k"); ok || m != nil {
+		t.Errorf("expected Pop to miss k, got %v, %t", m, ok)
+	}
+	if trie.Len() != 0 {
+		t.Errorf("expected Pop to remove k anyway, got %d keys", trie.Len())
+	}
+
+	trie = expiredTrie()
+	if err := trie.Rename("k", "n"); err == nil {
+		t.Error("expected Rename to miss k")
+	}
+
+	trie = expiredTrie()
+	trie.Add("n", "live")
+	if err := trie.Rename("n", "k"); err != nil {
+		t.Errorf("expected n to replace the expired k, got %v", err)
+	}
+	if m, ok := trie.Get("k"); !ok || m != "live" || trie.Len() != 1 {
+		t.Errorf("expected k with live as the only key, got %v, %t", m, ok)
+	}
+}
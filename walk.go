@@ -0,0 +1,88 @@
+package trie
+
+// Walk calls fn with every key of the trie and its meta, in
+// lexicographic order, until fn returns false. The key is built from
+// the path walked so far, so no slice of all keys is ever collected.
+func (t *Trie) Walk(fn func(key string, meta interface{}) bool) {
+	collectfunc(t.Root(), nil, func(key []rune, n *Node) bool {
+		return fn(string(key), n.Meta())
+	})
+}
+
+// WalkFrom is like Walk but only visits the keys starting with
+// prefix. The keys passed to fn include prefix.
+func (t *Trie) WalkFrom(prefix string, fn func(key string, meta interface{}) bool) {
+	prefix = t.normalize(prefix)
+	node := t.nodeAtPath(prefix)
+	if node == nil {
+		return
+	}
+
+	collectfunc(node, []rune(prefix), func(key []rune, n *Node) bool {
+		return fn(string(key), n.Meta())
+	})
+}
+
+// WalkBFS is like Walk but visits the keys level by level, so that
+// shorter keys come first, and keys of the same length are visited
+// in lexicographic order. The depth passed to fn is the number of
+// runes of the key.
+func (t *Trie) WalkBFS(fn func(key string, meta interface{}, depth int) bool) {
+	type item struct {
+		node *Node
+		pre  []rune
+	}
+
+	queue := []item{{node: t.Root()}}
+	for len(queue) > 0 {
+		it := queue[0]
+		queue = queue[1:]
+
+		children := it.node.children
+		for _, r := range sortedrunes(children) {
+			n := children[r]
+			if n.term {
+				if n.alive() && !fn(string(it.pre), n.Meta(), len(it.pre)) {
+					return
+				}
+				continue
+			}
+
+			pre := append(it.pre[:len(it.pre):len(it.pre)], r)
+			queue = append(queue, item{node: n, pre: pre})
+		}
+	}
+}
+
+// ToMap returns every key of the trie with its meta.
+func (t *Trie) ToMap() map[string]interface{} {
+	m := make(map[string]interface{}, t.Len())
+	collectfunc(t.Root(), nil, func(key []rune, n *Node) bool {
+		m[string(key)] = n.Meta()
+		return true
+	})
+
+	return m
+}
+
+// Entry is a key returned by Entries together with its meta.
+// It is the same type as KV.
+type Entry = KV
+
+// Entries returns every key of the trie with its meta, in
+// lexicographic order.
+func (t *Trie) Entries() []Entry {
+	res := make([]Entry, 0, t.Len())
+	collectfunc(t.Root(), nil, func(key []rune, n *Node) bool {
+		res = append(res, Entry{Key: string(key), Meta: n.Meta()})
+		return true
+	})
+
+	return res
+}
+
+// EntriesWithPrefix is like Entries but only returns the keys
+// starting with pre. It is the same as PrefixSearchWithMeta.
+func (t *Trie) EntriesWithPrefix(pre string) []Entry {
+	return t.PrefixSearchWithMeta(pre)
+}
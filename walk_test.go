@@ -0,0 +1,21 @@
+package trie
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEntries(t *testing.T) {
+	trie := NewTrie()
+	trie.Add("foobar", 2)
+	trie.Add("bar", 3)
+	trie.Add("foo", 1)
+
+	want := []Entry{{Key: "bar", Meta: 3}, {Key: "foo", Meta: 1}, {Key: "foobar", Meta: 2}}
+	if entries := trie.Entries(); !reflect.DeepEqual(entries, want) {
+		t.Errorf("expected %v, got %v", want, entries)
+	}
+	if entries := trie.EntriesWithPrefix("foo"); !reflect.DeepEqual(entries, want[1:]) {
+		t.Errorf("expected %v, got %v", want[1:], entries)
+	}
+}
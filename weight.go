@@ -0,0 +1,30 @@
+package trie
+
+// Increment adds delta to the weight of key, adding key with a nil
+// meta and a weight of 0 first if it isn't stored yet, and returns
+// the new weight. The weight is kept apart from the meta of the key.
+// A weight may drop to zero or below, the key stays in the trie
+// until it is removed.
+//
+// Without WithWeightFunc, TopK ranks keys by these weights.
+func (t *Trie) Increment(key string, delta float64) float64 {
+	node, _ := t.put(key, func(old interface{}, exists bool) interface{} {
+		return old
+	})
+
+	e := node.entry()
+	e.weight += delta
+	t.reweigh(node)
+	return e.weight
+}
+
+// Weight returns the weight of key, as built up by Increment. It
+// returns 0 if key isn't stored or was never incremented.
+func (t *Trie) Weight(key string) float64 {
+	node := t.live(t.terminal(t.normalize(key)))
+	if node == nil || node.ext == nil {
+		return 0
+	}
+
+	return node.ext.weight
+}
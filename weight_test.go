@@ -0,0 +1,44 @@
+package trie
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIncrement(t *testing.T) {
+	trie := NewTrie()
+	trie.Add("a", "meta")
+
+	if w := trie.Increment("a", 2); w != 2 {
+		t.Errorf("expected weight 2, got %v", w)
+	}
+	if w := trie.Increment("a", -3); w != -1 {
+		t.Errorf("expected weight -1, got %v", w)
+	}
+	if meta, _ := trie.Get("a"); meta != "meta" {
+		t.Errorf("expected Increment to keep the meta, got %v", meta)
+	}
+
+	if w := trie.Increment("b", 5); w != 5 || !trie.Contains("b") {
+		t.Errorf("expected Increment to add b with weight 5, got %v", w)
+	}
+
+	if w := trie.Weight("missing"); w != 0 {
+		t.Errorf("expected weight 0 for a missing key, got %v", w)
+	}
+}
+
+func TestIncrementTopK(t *testing.T) {
+	trie := NewTrie()
+	trie.Increment("ab", -5)
+	trie.Add("ac", nil)
+	trie.Increment("x", -3)
+
+	if keys := trie.TopK("", 1); !reflect.DeepEqual(keys, []string{"ac"}) {
+		t.Errorf("expected [ac], got %v", keys)
+	}
+
+	if keys := trie.TopK("", 0); !reflect.DeepEqual(keys, []string{"ac", "x", "ab"}) {
+		t.Errorf("expected [ac x ab], got %v", keys)
+	}
+}